@@ -0,0 +1,205 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/daemon"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+	"github.com/zjom/pom/internal/streak"
+	"github.com/zjom/pom/internal/tui"
+)
+
+var attachCmd = &cobra.Command{
+	Use:   "attach",
+	Short: "Show the running timer in the TUI; detaching leaves it running",
+	Args:  cobra.NoArgs,
+	RunE:  runAttach,
+}
+
+func init() {
+	rootCmd.AddCommand(attachCmd)
+	for _, c := range []struct{ cmd, short string }{
+		{daemon.CmdPause, "Pause the running timer"},
+		{daemon.CmdResume, "Resume the running timer, or start its next interval"},
+		{daemon.CmdSkip, "Skip to the next interval of the running timer"},
+		{daemon.CmdStop, "Stop the running timer"},
+	} {
+		rootCmd.AddCommand(&cobra.Command{
+			Use:   c.cmd,
+			Short: c.short,
+			Args:  cobra.NoArgs,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return runControl(c.cmd)
+			},
+		})
+	}
+}
+
+func runControl(name string) error {
+	req := daemon.Request{Cmd: name}
+	if name == daemon.CmdSkip {
+		// A flow session has no target to fall short of, so skipping one
+		// ends it as completed, as Enter does in the TUI.
+		if s, err := daemon.Call(daemon.CmdStatus); err == nil && s.SessionType == pomodoro.Flow && !s.Waiting {
+			req.Cmd = daemon.CmdComplete
+		}
+	}
+	s, err := daemon.Send(req)
+	if err != nil {
+		return err
+	}
+	if s.Stopped {
+		fmt.Println("Timer stopped.")
+		return nil
+	}
+	fmt.Println(describeState(*s))
+	return nil
+}
+
+// describeState renders s as one line, e.g.
+// "Focus 12:34 remaining [deep] - writing #docs (paused)".
+func describeState(s daemon.State) string {
+	var b strings.Builder
+	switch {
+	case s.Waiting:
+		fmt.Fprintf(&b, "Up next: %s (%s), waiting for pom resume", s.SessionType, s.Total)
+	case s.SessionType == pomodoro.Flow:
+		fmt.Fprintf(&b, "%s %s elapsed", s.SessionType, formatClock(s.Elapsed))
+	default:
+		fmt.Fprintf(&b, "%s %s remaining", s.SessionType, formatClock(s.Remaining))
+	}
+	if s.Profile != "" {
+		fmt.Fprintf(&b, " [%s]", s.Profile)
+	}
+	if s.Name != "" {
+		b.WriteString(" - " + s.Name)
+	}
+	for _, tag := range s.Tags {
+		b.WriteString(" #" + tag)
+	}
+	if s.Project != "" {
+		fmt.Fprintf(&b, " (project %s)", s.Project)
+	}
+	if s.Paused {
+		b.WriteString(" (paused)")
+	}
+	return b.String()
+}
+
+// formatClock formats d as MM:SS, the way the TUI shows it.
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// runAttach shows the running timer in the TUI until it stops or the user
+// detaches. A timer that stops while attached prints a summary of its run.
+func runAttach(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	sub, err := daemon.Attach()
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+	s, err := daemon.Call(daemon.CmdStatus)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	m := tui.NewModel(cfg, sub, *s)
+	err = loadGoals(store, &m)
+	store.Close()
+	if err != nil {
+		return err
+	}
+	m.LoadStreak = func(now time.Time) (streak.Summary, error) {
+		store, err := openStore(cfg)
+		if err != nil {
+			return streak.Summary{}, err
+		}
+		defer store.Close()
+		return streak.Load(context.Background(), store, cfg.DailyGoal, now)
+	}
+
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if err != nil {
+		return fmt.Errorf("run timer: %w", err)
+	}
+	fm, ok := final.(tui.Model)
+	if !ok {
+		return nil
+	}
+	if !fm.Stopped {
+		fmt.Println("Detached; the timer is still running. Use `pom attach` to return to it.")
+		return nil
+	}
+
+	result := struct {
+		Name              string    `json:"name,omitempty"`
+		Profile           string    `json:"profile,omitempty"`
+		Project           string    `json:"project,omitempty"`
+		Tags              []string  `json:"tags,omitempty"`
+		CompletedSessions int       `json:"completedSessions"`
+		StartTime         time.Time `json:"startTime"`
+		EndTime           time.Time `json:"endTime"`
+	}{
+		Name:              fm.State.Name,
+		Profile:           fm.State.Profile,
+		Project:           fm.State.Project,
+		Tags:              fm.State.Tags,
+		CompletedSessions: fm.State.SessionsDone,
+		StartTime:         fm.State.TimerStartedAt,
+		EndTime:           fm.State.At,
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		log.Printf("Failed to marshal JSON: %v", err)
+	} else {
+		fmt.Println(string(data))
+	}
+	return nil
+}
+
+// loadGoals fills in the TUI's progress towards the weekly goal of the
+// timer's project, if any, and towards the daily goal.
+func loadGoals(store storage.Store, m *tui.Model) error {
+	ctx := context.Background()
+	now := time.Now()
+	if name := m.State.Project; name != "" {
+		project, err := store.GetProject(ctx, name)
+		if err != nil {
+			return err
+		}
+		weekStart := startOfWeek(now)
+		stats, err := store.GetStatistics(ctx, storage.QueryFilter{Project: project.Name, From: &weekStart})
+		if err != nil {
+			return fmt.Errorf("query statistics: %w", err)
+		}
+		m.Project = project
+		m.WeekFocus = stats.FocusTime + stats.FlowTime
+	}
+
+	summary, err := streak.Load(ctx, store, m.Cfg.DailyGoal, now)
+	if err != nil {
+		return err
+	}
+	m.Streak = summary
+	return nil
+}
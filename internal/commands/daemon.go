@@ -0,0 +1,131 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/zjom/pom/internal/daemon"
+)
+
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run a timer without a user interface",
+	Long: `Run a timer in the foreground without a user interface. It is controlled
+with pom status, pause, resume, skip, stop and attach over a Unix socket in
+$XDG_RUNTIME_DIR. pom start runs one in the background and attaches to it.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	registerStartFlags(daemonCmd.Flags())
+
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	cfg, store, _, err := setupTimer(cmd)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ln, err := daemon.Listen()
+	if err != nil {
+		return err
+	}
+	defer ln.Close()
+
+	cp, err := recoverInterval(store, flagRecover)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var t *daemon.Timer
+	if cp != nil {
+		if cfg, err = resumeConfig(cmd, cfg, *cp); err != nil {
+			return err
+		}
+		t = daemon.ResumeTimer(cfg, *cp, store)
+	} else {
+		t = daemon.NewTimer(cfg, store)
+	}
+	go daemon.Serve(ln, t)
+	log.Printf("Timer listening on %s", daemon.SocketPath())
+	t.Run(ctx)
+	return nil
+}
+
+// spawnDaemon starts `pom daemon` in the background with the timer flags
+// given to `pom start`, waits for it to come up and returns its pid. Its log
+// goes next to the socket.
+func spawnDaemon(cmd *cobra.Command) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, fmt.Errorf("find pom executable: %w", err)
+	}
+
+	args := []string{"daemon"}
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if daemonCmd.Flags().Lookup(f.Name) == nil {
+			return
+		}
+		if sv, ok := f.Value.(pflag.SliceValue); ok {
+			for _, v := range sv.GetSlice() {
+				args = append(args, "--"+f.Name+"="+v)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+
+	logPath := strings.TrimSuffix(daemon.SocketPath(), filepath.Ext(daemon.SocketPath())) + ".log"
+	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("open daemon log: %w", err)
+	}
+	defer logFile.Close()
+
+	proc := exec.Command(exe, args...)
+	proc.Stdout = logFile
+	proc.Stderr = logFile
+	proc.SysProcAttr = detachedProcAttr()
+	if err := proc.Start(); err != nil {
+		return 0, fmt.Errorf("start daemon: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() { exited <- proc.Wait() }()
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = errors.New("exited early")
+			}
+			return 0, fmt.Errorf("daemon %v; see %s", err, logPath)
+		case <-deadline:
+			return 0, fmt.Errorf("daemon did not start listening within 5s; see %s", logPath)
+		case <-time.After(50 * time.Millisecond):
+		}
+		if _, err := daemon.Call(daemon.CmdStatus); err == nil {
+			break
+		}
+	}
+	return proc.Process.Pid, nil
+}
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+)
+
+var deleteCmd = &cobra.Command{
+	Use:   "delete <id>",
+	Short: "Delete a recorded session (revert with `pom undo`)",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runDelete,
+}
+
+func init() {
+	rootCmd.AddCommand(deleteCmd)
+}
+
+func runDelete(cmd *cobra.Command, args []string) error {
+	id, err := parseSessionID(args[0])
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.DeleteSession(context.Background(), id); err != nil {
+		return err
+	}
+	fmt.Printf("Deleted session %d. Run `pom undo` to restore it.\n", id)
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build unix
+
+package commands
+
+import "syscall"
+
+// detachedProcAttr puts a background timer in its own session so it
+// outlives the terminal that started it.
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
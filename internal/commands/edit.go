@@ -0,0 +1,165 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+var editCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Correct a recorded session",
+	Long: `Correct the name, tags, times or type of a recorded session. Session IDs
+are shown by ` + "`pom history`" + `. Changes can be reverted with ` + "`pom undo`" + `.
+
+Changing the times trims the session's pauses to fit them and recomputes its
+net, paused and planned durations.`,
+	Example: `  pom edit 42 --name "code review" --tag work
+  pom edit 42 --start "2026-10-17 09:05" --type flow`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEdit,
+}
+
+var (
+	editName  string
+	editTags  []string
+	editStart string
+	editEnd   string
+	editType  string
+)
+
+func init() {
+	editCmd.Flags().StringVarP(&editName, "name", "n", "", "new session label")
+	editCmd.Flags().StringArrayVarP(&editTags, "tag", "t", nil, "replace the session's tags (repeatable; pass --tag '' to clear)")
+	editCmd.Flags().StringVar(&editStart, "start", "", "new start time (YYYY-MM-DD HH:MM, local time)")
+	editCmd.Flags().StringVar(&editEnd, "end", "", "new end time (YYYY-MM-DD HH:MM, local time)")
+	editCmd.Flags().StringVar(&editType, "type", "", "new type: focus, flow, short-break, long-break")
+
+	rootCmd.AddCommand(editCmd)
+}
+
+func runEdit(cmd *cobra.Command, args []string) error {
+	id, err := parseSessionID(args[0])
+	if err != nil {
+		return err
+	}
+
+	flags := cmd.Flags()
+	if flags.NFlag() == 0 {
+		return fmt.Errorf("nothing to change: pass at least one of --name, --tag, --start, --end, --type")
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	s, err := store.GetSession(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if flags.Changed("name") {
+		s.Name = editName
+	}
+	if flags.Changed("tag") {
+		s.Tags = normalizeTags(editTags)
+	}
+	if flags.Changed("type") {
+		st, ok := parseSessionType(editType)
+		if !ok {
+			return fmt.Errorf("invalid --type %q: want focus, flow, short-break or long-break", editType)
+		}
+		s.SessionType = st
+	}
+	if flags.Changed("start") || flags.Changed("end") {
+		if flags.Changed("start") {
+			if s.StartedAt, err = time.ParseInLocation(logTimeLayout, editStart, time.Local); err != nil {
+				return fmt.Errorf("invalid --start time: %w", err)
+			}
+		}
+		if flags.Changed("end") {
+			if s.CompletedAt, err = time.ParseInLocation(logTimeLayout, editEnd, time.Local); err != nil {
+				return fmt.Errorf("invalid --end time: %w", err)
+			}
+		}
+		if !s.CompletedAt.After(s.StartedAt) {
+			return fmt.Errorf("session must end after it starts")
+		}
+		respan(s)
+	}
+
+	if err := store.UpdateSession(ctx, *s); err != nil {
+		return fmt.Errorf("update session: %w", err)
+	}
+	fmt.Printf("Updated session %d.\n", id)
+	return nil
+}
+
+// respan fits the pauses of s to its edited start and end times and
+// recomputes the durations that follow from them. Pauses outside the new
+// span are dropped and those across its ends are cut short. A session with
+// paused time but no recorded pauses keeps as much of it as still fits.
+//
+// A completed session ran to plan, so its planned length becomes its new
+// net length; one cut short keeps its plan unless it now ran past it.
+func respan(s *pomodoro.SessionResult) {
+	wall := int(s.Wall().Round(time.Second).Seconds())
+	if len(s.Pauses) > 0 {
+		var pauses []pomodoro.Pause
+		var paused time.Duration
+		for _, p := range s.Pauses {
+			p.StartedAt = later(p.StartedAt, s.StartedAt)
+			p.EndedAt = earlier(p.EndedAt, s.CompletedAt)
+			if p.EndedAt.After(p.StartedAt) {
+				pauses = append(pauses, p)
+				paused += p.EndedAt.Sub(p.StartedAt)
+			}
+		}
+		s.Pauses = pauses
+		s.PausedDuration = int(paused.Round(time.Second).Seconds())
+	}
+	s.PausedDuration = min(s.PausedDuration, wall)
+	s.Duration = wall - s.PausedDuration
+
+	if s.Status == pomodoro.Completed {
+		s.PlannedDuration = s.Duration
+	} else {
+		s.PlannedDuration = max(s.PlannedDuration, s.Duration)
+	}
+}
+
+func later(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func earlier(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
+
+// parseSessionID parses a session ID as shown by `pom history`.
+func parseSessionID(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid session ID %q", s)
+	}
+	return id, nil
+}
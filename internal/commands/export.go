@@ -0,0 +1,299 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export sessions as CSV, JSON, NDJSON or iCalendar",
+	Example: `  pom export --format csv --from 2026-10-01 --to 2026-10-31 --output october.csv
+  pom export --format ndjson | jq .durationSeconds
+  pom export --format ics --type focus --output focus.ics`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+var (
+	expFilter filterFlags
+	expFormat string
+	expOutput string
+)
+
+func init() {
+	expFilter.register(exportCmd.Flags())
+	exportCmd.Flags().StringVar(&expFormat, "format", "csv", "output format: csv, json, ndjson, ics")
+	exportCmd.Flags().StringVarP(&expOutput, "output", "o", "", "write to this file instead of stdout")
+
+	rootCmd.AddCommand(exportCmd)
+}
+
+// exportColumns are the CSV header and JSON field names of an export, in
+// order. They are part of pom's file format: add to the end, never rename.
+// JSON records also carry the session's pauses and interruptions, which
+// don't fit in a CSV row.
+var exportColumns = []string{
+	"id", "startedAt", "completedAt", "sessionType", "status", "name", "profile", "project", "tags", "note",
+	"durationSeconds", "plannedSeconds", "extendedSeconds", "pausedSeconds", "idleSecondsBefore",
+}
+
+// exportRecord is one exported session. Timestamps are RFC 3339 in local
+// time.
+type exportRecord struct {
+	ID                int64    `json:"id"`
+	StartedAt         string   `json:"startedAt"`
+	CompletedAt       string   `json:"completedAt"`
+	SessionType       string   `json:"sessionType"`
+	Status            string   `json:"status"`
+	Name              string   `json:"name"`
+	Profile           string   `json:"profile"`
+	Project           string   `json:"project"`
+	Tags              []string `json:"tags"`
+	Note              string   `json:"note"`
+	DurationSeconds   int      `json:"durationSeconds"`
+	PlannedSeconds    int      `json:"plannedSeconds"`
+	ExtendedSeconds   int      `json:"extendedSeconds"`
+	PausedSeconds     int      `json:"pausedSeconds"`
+	IdleSecondsBefore int      `json:"idleSecondsBefore"`
+
+	Pauses        []exportPause        `json:"pauses"`
+	Interruptions []exportInterruption `json:"interruptions"`
+}
+
+type exportPause struct {
+	StartedAt string `json:"startedAt"`
+	EndedAt   string `json:"endedAt"`
+}
+
+type exportInterruption struct {
+	Kind string `json:"kind"`
+	At   string `json:"at"`
+	Note string `json:"note"`
+}
+
+func newExportRecord(s pomodoro.SessionResult) exportRecord {
+	tags := s.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	pauses := make([]exportPause, len(s.Pauses))
+	for i, p := range s.Pauses {
+		pauses[i] = exportPause{
+			StartedAt: p.StartedAt.Local().Format(time.RFC3339),
+			EndedAt:   p.EndedAt.Local().Format(time.RFC3339),
+		}
+	}
+	interruptions := make([]exportInterruption, len(s.Interruptions))
+	for i, in := range s.Interruptions {
+		interruptions[i] = exportInterruption{
+			Kind: string(in.Kind),
+			At:   in.At.Local().Format(time.RFC3339),
+			Note: in.Note,
+		}
+	}
+	return exportRecord{
+		ID:                s.ID,
+		StartedAt:         s.StartedAt.Local().Format(time.RFC3339),
+		CompletedAt:       s.CompletedAt.Local().Format(time.RFC3339),
+		SessionType:       string(s.SessionType),
+		Status:            string(s.Status),
+		Name:              s.Name,
+		Profile:           s.Profile,
+		Project:           s.Project,
+		Tags:              tags,
+		Note:              s.Note,
+		DurationSeconds:   s.Duration,
+		PlannedSeconds:    s.PlannedDuration,
+		ExtendedSeconds:   s.ExtendedBy,
+		PausedSeconds:     s.PausedDuration,
+		IdleSecondsBefore: s.IdleBefore,
+		Pauses:            pauses,
+		Interruptions:     interruptions,
+	}
+}
+
+// csvRow returns r's fields in exportColumns order. Tags are joined with
+// semicolons.
+func (r exportRecord) csvRow() []string {
+	return []string{
+		strconv.FormatInt(r.ID, 10), r.StartedAt, r.CompletedAt, r.SessionType, r.Status,
+		r.Name, r.Profile, r.Project, strings.Join(r.Tags, ";"), r.Note,
+		strconv.Itoa(r.DurationSeconds), strconv.Itoa(r.PlannedSeconds), strconv.Itoa(r.ExtendedSeconds),
+		strconv.Itoa(r.PausedSeconds), strconv.Itoa(r.IdleSecondsBefore),
+	}
+}
+
+// sessionWriter writes exported sessions one at a time.
+type sessionWriter interface {
+	Write(s pomodoro.SessionResult) error
+	Close() error
+}
+
+// exportFormats are the values --format accepts.
+var exportFormats = []string{"csv", "json", "ndjson", "ics"}
+
+func checkExportFormat(format string) error {
+	if !slices.Contains(exportFormats, format) {
+		return fmt.Errorf("invalid --format %q: want csv, json, ndjson or ics", format)
+	}
+	return nil
+}
+
+func newSessionWriter(format string, w io.Writer) (sessionWriter, error) {
+	if err := checkExportFormat(format); err != nil {
+		return nil, err
+	}
+	switch format {
+	case "csv":
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(exportColumns)
+	case "json":
+		return &jsonWriter{w: w}, nil
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return &icsWriter{w: w, stamp: time.Now()}, nil
+}
+
+type csvWriter struct{ w *csv.Writer }
+
+func (c *csvWriter) Write(s pomodoro.SessionResult) error {
+	return c.w.Write(newExportRecord(s).csvRow())
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// jsonWriter writes a single JSON array, one element per line, without
+// holding the sessions in memory.
+type jsonWriter struct {
+	w io.Writer
+	n int
+}
+
+func (j *jsonWriter) Write(s pomodoro.SessionResult) error {
+	data, err := json.Marshal(newExportRecord(s))
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if j.n == 0 {
+		sep = "[\n"
+	}
+	j.n++
+	_, err = fmt.Fprintf(j.w, "%s  %s", sep, data)
+	return err
+}
+
+func (j *jsonWriter) Close() error {
+	if j.n == 0 {
+		_, err := io.WriteString(j.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(j.w, "\n]\n")
+	return err
+}
+
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func (n *ndjsonWriter) Write(s pomodoro.SessionResult) error {
+	return n.enc.Encode(newExportRecord(s))
+}
+
+func (n *ndjsonWriter) Close() error { return nil }
+
+func runExport(cmd *cobra.Command, args []string) error {
+	f, err := buildFilter(expFilter, 0)
+	if err != nil {
+		return err
+	}
+	// Checked before the output file is created, which would truncate it.
+	if err := checkExportFormat(expFormat); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var out io.Writer = os.Stdout
+	var file *os.File
+	if expOutput != "" && expOutput != "-" {
+		if file, err = os.Create(expOutput); err != nil {
+			return fmt.Errorf("create output file: %w", err)
+		}
+		// Only for early returns; the Close below reports write errors.
+		defer file.Close()
+		out = file
+	}
+	buf := bufio.NewWriter(out)
+
+	w, err := newSessionWriter(expFormat, buf)
+	if err != nil {
+		return err
+	}
+	n, err := exportSessions(context.Background(), store, f, w)
+	if err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("write export: %w", err)
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("write export: %w", err)
+		}
+	}
+
+	if expOutput != "" && expOutput != "-" {
+		fmt.Fprintf(os.Stderr, "Exported %d sessions to %s.\n", n, expOutput)
+	}
+	return nil
+}
+
+// exportSessions writes the sessions in store matching f to w and closes it,
+// returning how many were written.
+func exportSessions(ctx context.Context, store storage.Store, f storage.QueryFilter, w sessionWriter) (int, error) {
+	if ics, ok := w.(*icsWriter); ok {
+		var err error
+		if ics.dbID, err = store.DatabaseID(ctx); err != nil {
+			return 0, fmt.Errorf("read database ID: %w", err)
+		}
+		if ics.revisions, err = store.Revisions(ctx); err != nil {
+			return 0, fmt.Errorf("read edit history: %w", err)
+		}
+	}
+	n := 0
+	err := store.EachSession(ctx, f, func(s pomodoro.SessionResult) error {
+		n++
+		return w.Write(s)
+	})
+	if err != nil {
+		return n, fmt.Errorf("export sessions: %w", err)
+	}
+	return n, w.Close()
+}
@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+func TestExportColumns(t *testing.T) {
+	// Scripts depend on these names and their order.
+	want := []string{
+		"id", "startedAt", "completedAt", "sessionType", "status", "name", "profile", "project", "tags", "note",
+		"durationSeconds", "plannedSeconds", "extendedSeconds", "pausedSeconds", "idleSecondsBefore",
+	}
+	if !slices.Equal(exportColumns, want) {
+		t.Fatalf("columns changed:\n got %q\nwant %q", exportColumns, want)
+	}
+
+	// JSON records carry the same fields, in the same order, followed by
+	// the pauses and interruptions.
+	var fields []string
+	rt := reflect.TypeFor[exportRecord]()
+	for i := range rt.NumField() {
+		fields = append(fields, rt.Field(i).Tag.Get("json"))
+	}
+	if want := append(slices.Clone(want), "pauses", "interruptions"); !slices.Equal(fields, want) {
+		t.Errorf("JSON fields:\n got %q\nwant %q", fields, want)
+	}
+
+	if n := len(exportRecord{}.csvRow()); n != len(exportColumns) {
+		t.Errorf("CSV rows have %d fields, want %d", n, len(exportColumns))
+	}
+}
+
+func TestSessionWriters(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	sessions := []pomodoro.SessionResult{
+		{
+			ID:              1,
+			Name:            "report, \"final\"",
+			Project:         "work",
+			Tags:            []string{"admin", "q4"},
+			Note:            "two\nlines",
+			SessionType:     pomodoro.Focus,
+			Status:          pomodoro.Completed,
+			Duration:        1500,
+			PlannedDuration: 1500,
+			PausedDuration:  60,
+			StartedAt:       start,
+			CompletedAt:     start.Add(26 * time.Minute),
+			Pauses:          []pomodoro.Pause{{StartedAt: start.Add(5 * time.Minute), EndedAt: start.Add(6 * time.Minute)}},
+			Interruptions:   []pomodoro.Interruption{{Kind: pomodoro.External, At: start.Add(5 * time.Minute), Note: "door"}},
+		},
+		{
+			ID:              2,
+			SessionType:     pomodoro.ShortBreak,
+			Status:          pomodoro.Skipped,
+			Duration:        120,
+			PlannedDuration: 300,
+			IdleBefore:      30,
+			StartedAt:       start.Add(26 * time.Minute),
+			CompletedAt:     start.Add(28 * time.Minute),
+		},
+	}
+	local := func(t time.Time) string { return t.Local().Format(time.RFC3339) }
+	rows := [][]string{
+		exportColumns,
+		{"1", local(start), local(start.Add(26 * time.Minute)), "Focus Session", "completed", "report, \"final\"", "", "work",
+			"admin;q4", "two\nlines", "1500", "1500", "0", "60", "0"},
+		{"2", local(start.Add(26 * time.Minute)), local(start.Add(28 * time.Minute)), "Short Break", "skipped", "", "", "",
+			"", "", "120", "300", "0", "0", "30"},
+	}
+
+	// write returns what the writer for format makes of sessions.
+	write := func(t *testing.T, format string, sessions []pomodoro.SessionResult) []byte {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := newSessionWriter(format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range sessions {
+			if err := w.Write(s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	// Each format's records are decoded into maps so the JSON formats can
+	// be compared field by field.
+	records := func(t *testing.T, data []byte, array bool) []map[string]any {
+		t.Helper()
+		var out []map[string]any
+		if array {
+			if err := json.Unmarshal(data, &out); err != nil {
+				t.Fatalf("%v:\n%s", err, data)
+			}
+			return out
+		}
+		for line := range strings.Lines(string(data)) {
+			var r map[string]any
+			if err := json.Unmarshal([]byte(line), &r); err != nil {
+				t.Fatalf("%v: %q", err, line)
+			}
+			out = append(out, r)
+		}
+		return out
+	}
+	want := make([]map[string]any, len(sessions))
+	for i, s := range sessions {
+		data, err := json.Marshal(newExportRecord(s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal(data, &want[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("csv", func(t *testing.T) {
+		got, err := csv.NewReader(bytes.NewReader(write(t, "csv", sessions))).ReadAll()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, rows) {
+			t.Errorf("got\n%q\nwant\n%q", got, rows)
+		}
+		if got := string(write(t, "csv", nil)); got != strings.Join(exportColumns, ",")+"\n" {
+			t.Errorf("no sessions: got %q, want the header", got)
+		}
+	})
+
+	for _, format := range []string{"json", "ndjson"} {
+		t.Run(format, func(t *testing.T) {
+			data := write(t, format, sessions)
+			got := records(t, data, format == "json")
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got\n%v\nwant\n%v", got, want)
+			}
+			for _, r := range got {
+				for _, col := range exportColumns {
+					if _, ok := r[col]; !ok {
+						t.Errorf("record lacks %q", col)
+					}
+				}
+			}
+			if p := got[1]["pauses"]; !reflect.DeepEqual(p, []any{}) {
+				t.Errorf("no pauses: got %v, want []", p)
+			}
+			if in := got[0]["interruptions"].([]any); len(in) != 1 || in[0].(map[string]any)["note"] != "door" {
+				t.Errorf("interruptions: got %v", in)
+			}
+		})
+	}
+
+	empty := map[string]string{"json": "[]\n", "ndjson": ""}
+	for format, want := range empty {
+		if got := string(write(t, format, nil)); got != want {
+			t.Errorf("%s with no sessions: got %q, want %q", format, got, want)
+		}
+	}
+
+	if _, err := newSessionWriter("xml", &bytes.Buffer{}); err == nil {
+		t.Error("xml: got no error")
+	}
+}
+
+func TestExportBadFormatKeepsOutput(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.csv")
+	if err := os.WriteFile(path, []byte("precious"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	format, output := expFormat, expOutput
+	t.Cleanup(func() { expFormat, expOutput = format, output })
+	expFormat, expOutput = "xlsx", path
+
+	if err := runExport(exportCmd, nil); err == nil || !strings.Contains(err.Error(), "--format") {
+		t.Errorf("got %v, want an invalid --format error", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "precious" {
+		t.Errorf("output file: got %q, %v; want it untouched", data, err)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/spf13/cobra"
 
-	"github.com/zjom/pom/internal/storage"
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
 )
 
 var historyCmd = &cobra.Command{
@@ -19,41 +21,41 @@ var historyCmd = &cobra.Command{
 }
 
 var (
-	histName  string
-	histFrom  string
-	histTo    string
-	histType  string
-	histLimit int
-	histJSON  bool
+	histFilter filterFlags
+	histLimit  int
+	histSearch string
+	histJSON   bool
 )
 
 func init() {
-	historyCmd.Flags().StringVar(&histName, "name", "", "filter by session name")
-	historyCmd.Flags().StringVar(&histFrom, "from", "", "start date (YYYY-MM-DD)")
-	historyCmd.Flags().StringVar(&histTo, "to", "", "end date (YYYY-MM-DD)")
-	historyCmd.Flags().StringVar(&histType, "type", "", "filter by type: focus, short-break, long-break")
+	histFilter.register(historyCmd.Flags())
 	historyCmd.Flags().IntVar(&histLimit, "limit", 0, "max number of sessions to show")
+	historyCmd.Flags().StringVar(&histSearch, "search", "", "only sessions whose name or note mentions these words")
 	historyCmd.Flags().BoolVar(&histJSON, "json", false, "output as JSON")
 
 	rootCmd.AddCommand(historyCmd)
 }
 
 func runHistory(cmd *cobra.Command, args []string) error {
-	dbPath, err := storage.DefaultDBPath()
+	cfg, err := config.Load("")
 	if err != nil {
-		return fmt.Errorf("determine database path: %w", err)
+		return err
 	}
 
-	store, err := storage.NewSQLiteStore(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
-		return fmt.Errorf("open database: %w", err)
+		return err
 	}
 	defer store.Close()
 
-	f, err := buildFilter(histName, histFrom, histTo, histType, histLimit)
+	f, err := buildFilter(histFilter, histLimit)
 	if err != nil {
 		return err
 	}
+	if histSearch != "" && strings.Trim(histSearch, "* \t") == "" {
+		return fmt.Errorf("--search needs at least one word")
+	}
+	f.Search = histSearch
 
 	sessions, err := store.ListSessions(context.Background(), f)
 	if err != nil {
@@ -74,24 +76,33 @@ func runHistory(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
 	fmt.Println(headerStyle.Render("📋 Session History"))
 	fmt.Println()
 
 	var rows [][]string
 	for _, s := range sessions {
 		rows = append(rows, []string{
+			fmt.Sprintf("%d", s.ID),
 			s.StartedAt.Local().Format("2006-01-02 15:04"),
 			s.Name,
+			s.Profile,
+			s.Project,
+			strings.Join(s.Tags, ", "),
 			string(s.SessionType),
-			formatDuration(s.CompletedAt.Sub(s.StartedAt)),
+			string(s.Status),
+			pomodoro.FormatDuration(s.Net()),
+			pomodoro.FormatDuration(s.Wall()),
+			fmt.Sprintf("%d", len(s.Pauses)),
+			fmt.Sprintf("%d/%d", s.CountInterruptions(pomodoro.Internal), s.CountInterruptions(pomodoro.External)),
+			s.Note,
 		})
 	}
 
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
-		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("238"))).
-		Headers("Date", "Name", "Type", "Duration").
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Theme.Border))).
+		Headers("ID", "Date", "Name", "Profile", "Project", "Tags", "Type", "Status", "Net", "Wall", "Pauses", "Int/Ext", "Note").
 		Rows(rows...)
 
 	fmt.Println(t)
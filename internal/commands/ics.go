@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+const icsTimeLayout = "20060102T150405Z"
+
+// icsWriter writes sessions as iCalendar VEVENTs (RFC 5545). UIDs are derived
+// from session IDs and the database's ID, so importing a newer export into a
+// calendar updates the events from an older one instead of duplicating them,
+// while sessions from different databases never collide. Edits recorded in
+// the undo log bump an event's SEQUENCE and LAST-MODIFIED.
+type icsWriter struct {
+	w         io.Writer
+	stamp     time.Time // when the export was made
+	dbID      string
+	revisions map[int64]storage.Revision
+	started   bool
+	err       error
+}
+
+func (c *icsWriter) line(name, value string) {
+	if c.err != nil {
+		return
+	}
+	_, c.err = io.WriteString(c.w, foldICSLine(name+":"+value)+"\r\n")
+}
+
+func (c *icsWriter) begin() {
+	if c.started {
+		return
+	}
+	c.started = true
+	c.line("BEGIN", "VCALENDAR")
+	c.line("VERSION", "2.0")
+	c.line("PRODID", "-//pom//pom//EN")
+	c.line("CALSCALE", "GREGORIAN")
+}
+
+func (c *icsWriter) Write(s pomodoro.SessionResult) error {
+	c.begin()
+
+	summary := s.Name
+	if summary == "" {
+		summary = string(s.SessionType)
+	}
+	for _, tag := range s.Tags {
+		summary += " #" + tag
+	}
+	desc := fmt.Sprintf("%s, %s, %s net", s.SessionType, s.Status, pomodoro.FormatDuration(s.Net()))
+	if s.Note != "" {
+		desc += "\n" + s.Note
+	}
+
+	c.line("BEGIN", "VEVENT")
+	c.line("UID", fmt.Sprintf("session-%d-%s@pom", s.ID, c.dbID))
+	c.line("DTSTAMP", c.stamp.UTC().Format(icsTimeLayout))
+	// Sessions are written when they end, so until edited that is when they
+	// were last modified.
+	rev := c.revisions[s.ID]
+	modified := s.CompletedAt
+	if rev.Sequence > 0 {
+		modified = rev.Modified
+	}
+	c.line("LAST-MODIFIED", modified.UTC().Format(icsTimeLayout))
+	c.line("SEQUENCE", fmt.Sprint(rev.Sequence))
+	c.line("DTSTART", s.StartedAt.UTC().Format(icsTimeLayout))
+	c.line("DTEND", s.CompletedAt.UTC().Format(icsTimeLayout))
+	c.line("SUMMARY", escapeICSText(summary))
+	c.line("DESCRIPTION", escapeICSText(desc))
+	if len(s.Tags) > 0 {
+		tags := make([]string, len(s.Tags))
+		for i, t := range s.Tags {
+			tags[i] = escapeICSText(t)
+		}
+		c.line("CATEGORIES", strings.Join(tags, ","))
+	}
+	c.line("TRANSP", "TRANSPARENT")
+	c.line("END", "VEVENT")
+	return c.err
+}
+
+func (c *icsWriter) Close() error {
+	c.begin()
+	c.line("END", "VCALENDAR")
+	return c.err
+}
+
+// escapeICSText escapes a TEXT property value.
+func escapeICSText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+// foldICSLine splits a content line into 75-octet pieces joined by CRLF and
+// a space, without breaking UTF-8 sequences.
+func foldICSLine(line string) string {
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts towards the limit
+	}
+	b.WriteString(line)
+	return b.String()
+}
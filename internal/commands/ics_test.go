@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+func TestEscapeICSText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain", "plain"},
+		{`a,b;c\d`, `a\,b\;c\\d`},
+		{`\,`, `\\\,`},
+		{"one\ntwo\r\nthree", `one\ntwo\nthree`},
+		{"Café ☕, 日本語; naïve\n", `Café ☕\, 日本語\; naïve\n`},
+	}
+	for _, tt := range tests {
+		if got := escapeICSText(tt.in); got != tt.want {
+			t.Errorf("%q: got %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+// unfoldICS undoes foldICSLine on the content lines of an iCalendar file.
+func unfoldICS(s string) []string {
+	return strings.Split(strings.TrimSuffix(strings.ReplaceAll(s, "\r\n ", ""), "\r\n"), "\r\n")
+}
+
+func TestFoldICSLine(t *testing.T) {
+	tests := []struct {
+		name  string
+		line  string
+		lines int
+	}{
+		{"short", "SUMMARY:Report", 1},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67), 1},
+		{"76 octets", "SUMMARY:" + strings.Repeat("a", 68), 2},
+		{"three-byte runes", "SUMMARY:" + strings.Repeat("日本語", 30), 4},
+		{"runes across the limit", "SUMMARY:" + strings.Repeat("a", 66) + "☕☕☕", 2},
+		{"four-byte runes", "DESCRIPTION:" + strings.Repeat("🍅", 50), 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldICSLine(tt.line)
+			parts := strings.Split(folded, "\r\n")
+			if len(parts) != tt.lines {
+				t.Errorf("got %d lines, want %d: %q", len(parts), tt.lines, folded)
+			}
+			for i, p := range parts {
+				if len(p) > 75 {
+					t.Errorf("line %d is %d octets: %q", i, len(p), p)
+				}
+				if i > 0 && !strings.HasPrefix(p, " ") {
+					t.Errorf("line %d does not start with a space: %q", i, p)
+				}
+				if !utf8.ValidString(p) {
+					t.Errorf("line %d splits a character: %q", i, p)
+				}
+			}
+			if got := unfoldICS(folded + "\r\n"); len(got) != 1 || got[0] != tt.line {
+				t.Errorf("unfolded to %q, want %q", got, tt.line)
+			}
+		})
+	}
+}
+
+// icsProps returns the properties of the VEVENTs in an iCalendar file, one
+// map per event.
+func icsProps(s string) []map[string]string {
+	var events []map[string]string
+	for _, line := range unfoldICS(s) {
+		name, value, _ := strings.Cut(line, ":")
+		switch {
+		case line == "BEGIN:VEVENT":
+			events = append(events, make(map[string]string))
+		case len(events) > 0:
+			events[len(events)-1][name] = value
+		}
+	}
+	return events
+}
+
+func TestICSWriter(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	err = store.SaveSession(ctx, pomodoro.SessionResult{
+		Name:            "Überarbeitung, Kapitel; 日本語 " + strings.Repeat("☕", 30),
+		Tags:            []string{"a,b", "writing"},
+		Note:            "first line\nsecond; with, commas \\ and a backslash",
+		SessionType:     pomodoro.Focus,
+		Status:          pomodoro.Completed,
+		Duration:        1500,
+		PlannedDuration: 1500,
+		StartedAt:       start,
+		CompletedAt:     start.Add(25 * time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	export := func() map[string]string {
+		t.Helper()
+		var buf bytes.Buffer
+		w, err := newSessionWriter("ics", &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n, err := exportSessions(ctx, store, storage.QueryFilter{}, w); err != nil || n != 1 {
+			t.Fatalf("export: got %d sessions, %v", n, err)
+		}
+		out := buf.String()
+		for i, line := range strings.SplitAfter(out, "\r\n") {
+			if len(strings.TrimSuffix(line, "\r\n")) > 75 {
+				t.Errorf("line %d is longer than 75 octets: %q", i, line)
+			}
+		}
+		if !strings.HasPrefix(out, "BEGIN:VCALENDAR\r\n") || !strings.HasSuffix(out, "END:VCALENDAR\r\n") {
+			t.Errorf("not a calendar:\n%s", out)
+		}
+		events := icsProps(out)
+		if len(events) != 1 {
+			t.Fatalf("got %d events, want 1:\n%s", len(events), out)
+		}
+		return events[0]
+	}
+
+	first := export()
+	dbID, err := store.DatabaseID(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uid := "session-1-" + dbID + "@pom"; dbID == "" || first["UID"] != uid {
+		t.Errorf("UID: got %q, want %q", first["UID"], uid)
+	}
+	want := map[string]string{
+		"SUMMARY":     `Überarbeitung\, Kapitel\; 日本語 ` + strings.Repeat("☕", 30) + ` #a\,b #writing`,
+		"DESCRIPTION": `Focus Session\, completed\, 25m 0s net\nfirst line\nsecond\; with\, commas \\ and a backslash`,
+		"CATEGORIES":  `a\,b,writing`,
+		"DTSTART":     "20261016T090000Z",
+		"DTEND":       "20261016T092500Z",
+		"SEQUENCE":    "0",
+	}
+	for name, v := range want {
+		if first[name] != v {
+			t.Errorf("%s: got %q, want %q", name, first[name], v)
+		}
+	}
+
+	if again := export(); again["UID"] != first["UID"] || again["SEQUENCE"] != "0" {
+		t.Errorf("second export: UID %q, SEQUENCE %s; want %q, 0", again["UID"], again["SEQUENCE"], first["UID"])
+	}
+
+	s, err := store.GetSession(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Name = "Edited"
+	if err := store.UpdateSession(ctx, *s); err != nil {
+		t.Fatal(err)
+	}
+	edited := export()
+	if edited["UID"] != first["UID"] {
+		t.Errorf("UID changed after an edit: got %q, want %q", edited["UID"], first["UID"])
+	}
+	if edited["SEQUENCE"] != "1" || edited["SUMMARY"] != `Edited #a\,b #writing` {
+		t.Errorf("after an edit: SEQUENCE %s, SUMMARY %q; want 1, %q", edited["SEQUENCE"], edited["SUMMARY"], `Edited #a\,b #writing`)
+	}
+	if edited["LAST-MODIFIED"] <= first["LAST-MODIFIED"] {
+		t.Errorf("LAST-MODIFIED went from %s to %s after an edit", first["LAST-MODIFIED"], edited["LAST-MODIFIED"])
+	}
+}
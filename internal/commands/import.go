@@ -0,0 +1,579 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Import sessions from pom exports or other trackers",
+	Long: `Import sessions from a file. Supported formats:
+
+  pom     CSV written by ` + "`pom export --format csv`" + `
+  json    JSON written by ` + "`pom export --format json`" + `
+  ndjson  NDJSON written by ` + "`pom export --format ndjson`" + `
+  toggl   Toggl Track detailed CSV export
+  csv     any CSV with a header row, mapped with the --*-col flags
+
+The format is detected from the file unless --format is given. Sessions that
+start at the same second as an existing session of the same type are skipped,
+and projects that don't exist yet are created. The import is all or nothing:
+if any session fails to save, none are.`,
+	Example: `  pom import october.csv --dry-run
+  pom import toggl.csv --format toggl
+  pom import log.csv --format csv --start-col When --duration-col Minutes --name-col Task`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+var (
+	impFormat   string
+	impDryRun   bool
+	impStartCol string
+	impEndCol   string
+	impDurCol   string
+	impNameCol  string
+	impTypeCol  string
+	impTagsCol  string
+	impLayout   string
+	impType     string
+)
+
+func init() {
+	importCmd.Flags().StringVar(&impFormat, "format", "auto", "input format: auto, pom, json, ndjson, toggl, csv")
+	importCmd.Flags().BoolVar(&impDryRun, "dry-run", false, "report what would be imported without writing anything")
+
+	importCmd.Flags().StringVar(&impStartCol, "start-col", "start", "csv: column holding the start time")
+	importCmd.Flags().StringVar(&impEndCol, "end-col", "", "csv: column holding the end time")
+	importCmd.Flags().StringVar(&impDurCol, "duration-col", "", "csv: column holding the duration (25m, 0:25:00 or minutes)")
+	importCmd.Flags().StringVar(&impNameCol, "name-col", "", "csv: column holding the session name")
+	importCmd.Flags().StringVar(&impTypeCol, "type-col", "", "csv: column holding the session type")
+	importCmd.Flags().StringVar(&impTagsCol, "tags-col", "", "csv: column holding comma-separated tags")
+	importCmd.Flags().StringVar(&impLayout, "time-layout", "2006-01-02 15:04", "csv: Go time layout of the time columns, read as local time")
+	importCmd.Flags().StringVar(&impType, "type", "focus", "csv: session type for rows without a type column")
+
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	format := impFormat
+	if format == "auto" {
+		format = detectImportFormat(args[0], data)
+	}
+	sessions, err := readImport(format, data)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", args[0], err)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	plan, err := planImport(ctx, store, sessions)
+	if err != nil {
+		return err
+	}
+
+	if impDryRun {
+		fmt.Printf("Would insert %d sessions, skip %d duplicates%s (%s format).\n",
+			len(plan.fresh), plan.skipped, projectList(", and create projects", plan.projects), format)
+		return nil
+	}
+
+	created, err := store.ImportSessions(ctx, plan.fresh)
+	if err != nil {
+		return fmt.Errorf("import sessions (nothing was saved): %w", err)
+	}
+	fmt.Printf("Inserted %d sessions, skipped %d duplicates%s.\n",
+		len(plan.fresh), plan.skipped, projectList(", created projects", created))
+	return nil
+}
+
+// readImport parses data in format, one of the --format names other than
+// auto.
+func readImport(format string, data []byte) ([]pomodoro.SessionResult, error) {
+	switch format {
+	case "pom":
+		return readPomCSV(data)
+	case "json":
+		return readPomJSON(data)
+	case "ndjson":
+		return readPomNDJSON(data)
+	case "toggl":
+		return readTogglCSV(data)
+	case "csv":
+		return readGenericCSV(data)
+	}
+	return nil, fmt.Errorf("invalid --format %q: want auto, pom, json, ndjson, toggl or csv", format)
+}
+
+// importPlan is what an import will do: save fresh, skip skipped duplicates
+// and create the named projects, which sessions in fresh use but don't
+// exist yet.
+type importPlan struct {
+	fresh    []pomodoro.SessionResult
+	skipped  int
+	projects []string
+}
+
+func planImport(ctx context.Context, store storage.Store, sessions []pomodoro.SessionResult) (importPlan, error) {
+	var plan importPlan
+	seen := make(map[importKey]bool)
+	err := store.EachSession(ctx, storage.QueryFilter{}, func(s pomodoro.SessionResult) error {
+		seen[keyOf(s)] = true
+		return nil
+	})
+	if err != nil {
+		return plan, fmt.Errorf("load existing sessions: %w", err)
+	}
+
+	for _, s := range sessions {
+		if k := keyOf(s); !seen[k] {
+			seen[k] = true
+			plan.fresh = append(plan.fresh, s)
+		}
+	}
+	plan.skipped = len(sessions) - len(plan.fresh)
+
+	for _, s := range plan.fresh {
+		if s.Project == "" || slices.Contains(plan.projects, s.Project) {
+			continue
+		}
+		_, err := store.GetProject(ctx, s.Project)
+		if errors.Is(err, storage.ErrProjectNotFound) {
+			plan.projects = append(plan.projects, s.Project)
+		} else if err != nil {
+			return plan, err
+		}
+	}
+	return plan, nil
+}
+
+// projectList returns prefix followed by the quoted project names, or ""
+// if there are none.
+func projectList(prefix string, names []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(names))
+	for i, n := range names {
+		quoted[i] = strconv.Quote(n)
+	}
+	return prefix + " " + strings.Join(quoted, ", ")
+}
+
+// importKey identifies a session for deduplication: two sessions of the same
+// type starting in the same second are taken to be the same session.
+type importKey struct {
+	start int64
+	typ   pomodoro.SessionType
+}
+
+func keyOf(s pomodoro.SessionResult) importKey {
+	return importKey{s.StartedAt.Unix(), s.SessionType}
+}
+
+// detectImportFormat guesses the format of data from the file name and the
+// CSV header.
+func detectImportFormat(path string, data []byte) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ndjson", ".jsonl":
+		return "ndjson"
+	case ".json":
+		return "json"
+	}
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		return "ndjson"
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		return "json"
+	}
+
+	header, err := csv.NewReader(bytes.NewReader(data)).Read()
+	if err != nil {
+		return "csv"
+	}
+	switch {
+	case slices.Contains(header, "startedAt") && slices.Contains(header, "sessionType"):
+		return "pom"
+	case slices.Contains(header, "Start date") && slices.Contains(header, "Start time"):
+		return "toggl"
+	}
+	return "csv"
+}
+
+// csvTable is a CSV file read into memory with its header row indexed.
+type csvTable struct {
+	cols map[string]int
+	rows [][]string
+}
+
+func readCSV(data []byte) (*csvTable, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty file")
+	}
+
+	t := &csvTable{cols: make(map[string]int), rows: records[1:]}
+	for i, name := range records[0] {
+		// Spreadsheet exports often start with a byte order mark.
+		t.cols[strings.TrimPrefix(strings.TrimSpace(name), "\ufeff")] = i
+	}
+	return t, nil
+}
+
+// require reports an error naming any of cols the header lacks. Empty names
+// are ignored.
+func (t *csvTable) require(cols ...string) error {
+	for _, c := range cols {
+		if _, ok := t.cols[c]; c != "" && !ok {
+			return fmt.Errorf("no %q column", c)
+		}
+	}
+	return nil
+}
+
+// get returns the value of column col in row, or "" if either is missing.
+func (t *csvTable) get(row []string, col string) string {
+	i, ok := t.cols[col]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+func readPomCSV(data []byte) ([]pomodoro.SessionResult, error) {
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.require("startedAt", "completedAt", "sessionType", "durationSeconds"); err != nil {
+		return nil, err
+	}
+
+	var sessions []pomodoro.SessionResult
+	for i, row := range t.rows {
+		var r exportRecord
+		var errs []error
+		atoi := func(col string) int {
+			v := t.get(row, col)
+			if v == "" {
+				return 0
+			}
+			n, err := strconv.Atoi(v)
+			errs = append(errs, err)
+			return n
+		}
+		r.StartedAt = t.get(row, "startedAt")
+		r.CompletedAt = t.get(row, "completedAt")
+		r.SessionType = t.get(row, "sessionType")
+		r.Status = t.get(row, "status")
+		r.Name = t.get(row, "name")
+		r.Profile = t.get(row, "profile")
+		r.Project = t.get(row, "project")
+		if tags := t.get(row, "tags"); tags != "" {
+			r.Tags = strings.Split(tags, ";")
+		}
+		r.Note = t.get(row, "note")
+		r.DurationSeconds = atoi("durationSeconds")
+		r.PlannedSeconds = atoi("plannedSeconds")
+		r.ExtendedSeconds = atoi("extendedSeconds")
+		r.PausedSeconds = atoi("pausedSeconds")
+		r.IdleSecondsBefore = atoi("idleSecondsBefore")
+
+		s, err := r.session()
+		if err := errors.Join(append(errs, err)...); err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+func readPomNDJSON(data []byte) ([]pomodoro.SessionResult, error) {
+	var sessions []pomodoro.SessionResult
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var r exportRecord
+		if err := json.Unmarshal(sc.Bytes(), &r); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		s, err := r.session()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, sc.Err()
+}
+
+func readPomJSON(data []byte) ([]pomodoro.SessionResult, error) {
+	var records []exportRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	sessions := make([]pomodoro.SessionResult, len(records))
+	for i, r := range records {
+		s, err := r.session()
+		if err != nil {
+			return nil, fmt.Errorf("record %d: %w", i+1, err)
+		}
+		sessions[i] = s
+	}
+	return sessions, nil
+}
+
+// session converts an exported record back into a session. The ID is dropped
+// so the importing database assigns its own.
+func (r exportRecord) session() (pomodoro.SessionResult, error) {
+	start, err := time.Parse(time.RFC3339, r.StartedAt)
+	if err != nil {
+		return pomodoro.SessionResult{}, fmt.Errorf("startedAt: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, r.CompletedAt)
+	if err != nil {
+		return pomodoro.SessionResult{}, fmt.Errorf("completedAt: %w", err)
+	}
+	if r.SessionType == "" {
+		return pomodoro.SessionResult{}, fmt.Errorf("missing sessionType")
+	}
+	status := pomodoro.SessionStatus(r.Status)
+	if status == "" {
+		status = pomodoro.Completed
+	}
+	var pauses []pomodoro.Pause
+	for i, p := range r.Pauses {
+		from, err := time.Parse(time.RFC3339, p.StartedAt)
+		if err != nil {
+			return pomodoro.SessionResult{}, fmt.Errorf("pauses[%d].startedAt: %w", i, err)
+		}
+		to, err := time.Parse(time.RFC3339, p.EndedAt)
+		if err != nil {
+			return pomodoro.SessionResult{}, fmt.Errorf("pauses[%d].endedAt: %w", i, err)
+		}
+		pauses = append(pauses, pomodoro.Pause{StartedAt: from, EndedAt: to})
+	}
+	var interruptions []pomodoro.Interruption
+	for i, in := range r.Interruptions {
+		kind := pomodoro.InterruptionKind(in.Kind)
+		if kind != pomodoro.Internal && kind != pomodoro.External {
+			return pomodoro.SessionResult{}, fmt.Errorf("interruptions[%d]: unknown kind %q", i, in.Kind)
+		}
+		at, err := time.Parse(time.RFC3339, in.At)
+		if err != nil {
+			return pomodoro.SessionResult{}, fmt.Errorf("interruptions[%d].at: %w", i, err)
+		}
+		interruptions = append(interruptions, pomodoro.Interruption{Kind: kind, At: at, Note: in.Note})
+	}
+	return pomodoro.SessionResult{
+		Name:            r.Name,
+		Profile:         r.Profile,
+		Project:         r.Project,
+		Tags:            normalizeTags(r.Tags),
+		Note:            r.Note,
+		SessionType:     importType(r.SessionType),
+		Status:          status,
+		Duration:        r.DurationSeconds,
+		PlannedDuration: r.PlannedSeconds,
+		ExtendedBy:      r.ExtendedSeconds,
+		PausedDuration:  r.PausedSeconds,
+		IdleBefore:      r.IdleSecondsBefore,
+		StartedAt:       start,
+		CompletedAt:     end,
+		Pauses:          pauses,
+		Interruptions:   interruptions,
+	}, nil
+}
+
+// readTogglCSV reads a Toggl Track detailed report. The description becomes
+// the session name; every entry is recorded as a completed focus session.
+func readTogglCSV(data []byte) ([]pomodoro.SessionResult, error) {
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.require("Start date", "Start time", "End date", "End time"); err != nil {
+		return nil, err
+	}
+
+	const layout = "2006-01-02 15:04:05"
+	var sessions []pomodoro.SessionResult
+	for i, row := range t.rows {
+		start, err := time.ParseInLocation(layout, t.get(row, "Start date")+" "+t.get(row, "Start time"), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: start: %w", i+2, err)
+		}
+		end, err := time.ParseInLocation(layout, t.get(row, "End date")+" "+t.get(row, "End time"), time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: end: %w", i+2, err)
+		}
+		s, err := importedSession(start, end, pomodoro.Focus)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		s.Name = t.get(row, "Description")
+		s.Project = t.get(row, "Project")
+		s.Tags = splitTags(t.get(row, "Tags"))
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// readGenericCSV reads a CSV file whose columns are named by the --*-col
+// flags.
+func readGenericCSV(data []byte) ([]pomodoro.SessionResult, error) {
+	if (impEndCol == "") == (impDurCol == "") {
+		return nil, fmt.Errorf("exactly one of --end-col and --duration-col is required")
+	}
+	defType, ok := parseSessionType(impType)
+	if !ok {
+		return nil, fmt.Errorf("invalid --type %q: want focus, flow, short-break or long-break", impType)
+	}
+
+	t, err := readCSV(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := t.require(impStartCol, impEndCol, impDurCol, impNameCol, impTypeCol, impTagsCol); err != nil {
+		return nil, err
+	}
+
+	var sessions []pomodoro.SessionResult
+	for i, row := range t.rows {
+		start, err := parseImportTime(t.get(row, impStartCol))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+2, impStartCol, err)
+		}
+		var end time.Time
+		if impEndCol != "" {
+			if end, err = parseImportTime(t.get(row, impEndCol)); err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", i+2, impEndCol, err)
+			}
+		} else {
+			d, err := parseImportDuration(t.get(row, impDurCol))
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %s: %w", i+2, impDurCol, err)
+			}
+			end = start.Add(d)
+		}
+
+		typ := defType
+		if v := t.get(row, impTypeCol); v != "" {
+			typ = importType(v)
+		}
+		s, err := importedSession(start, end, typ)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		s.Name = t.get(row, impNameCol)
+		s.Tags = splitTags(t.get(row, impTagsCol))
+		sessions = append(sessions, s)
+	}
+	return sessions, nil
+}
+
+// importedSession returns a completed session of type typ covering
+// [start, end).
+func importedSession(start, end time.Time, typ pomodoro.SessionType) (pomodoro.SessionResult, error) {
+	if !end.After(start) {
+		return pomodoro.SessionResult{}, fmt.Errorf("session must end after it starts")
+	}
+	secs := int(end.Sub(start).Round(time.Second).Seconds())
+	return pomodoro.SessionResult{
+		SessionType:     typ,
+		Status:          pomodoro.Completed,
+		Duration:        secs,
+		PlannedDuration: secs,
+		StartedAt:       start,
+		CompletedAt:     end,
+	}, nil
+}
+
+// importType accepts a --type style name ("short-break") or a stored session
+// type ("Short Break"). Anything else is kept as a custom interval type.
+func importType(s string) pomodoro.SessionType {
+	if st, ok := parseSessionType(strings.ToLower(s)); ok {
+		return st
+	}
+	return pomodoro.SessionType(s)
+}
+
+// parseImportTime parses v with --time-layout in local time, falling back to
+// RFC 3339.
+func parseImportTime(v string) (time.Time, error) {
+	t, err := time.ParseInLocation(impLayout, v, time.Local)
+	if err == nil {
+		return t, nil
+	}
+	if t, rfcErr := time.Parse(time.RFC3339, v); rfcErr == nil {
+		return t, nil
+	}
+	return time.Time{}, err
+}
+
+// parseImportDuration accepts Go durations ("25m"), clock durations
+// ("0:25:00" or "25:00") and plain numbers of minutes.
+func parseImportDuration(v string) (time.Duration, error) {
+	if n, err := strconv.ParseFloat(v, 64); err == nil {
+		return time.Duration(n * float64(time.Minute)), nil
+	}
+	if parts := strings.Split(v, ":"); len(parts) == 2 || len(parts) == 3 {
+		var d time.Duration
+		for _, p := range parts {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", v)
+			}
+			d = d*60 + time.Duration(n)
+		}
+		return d * time.Second, nil
+	}
+	return time.ParseDuration(v)
+}
+
+// splitTags splits a comma-separated tag list.
+func splitTags(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return normalizeTags(strings.Split(v, ","))
+}
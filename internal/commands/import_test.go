@@ -0,0 +1,403 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+func TestDetectImportFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		data string
+		want string
+	}{
+		{name: "ndjson extension", path: "log.ndjson", data: "startedAt,sessionType\n", want: "ndjson"},
+		{name: "jsonl extension", path: "log.JSONL", data: "", want: "ndjson"},
+		{name: "json extension", path: "log.json", data: "{}", want: "json"},
+		{name: "json lines", path: "log.txt", data: "\n  {\"startedAt\": \"\"}\n", want: "ndjson"},
+		{name: "json array", path: "log.txt", data: "[\n]", want: "json"},
+		{name: "pom csv", path: "log.csv", data: "id,startedAt,completedAt,sessionType\n", want: "pom"},
+		{name: "toggl", path: "report.csv", data: "User,Description,Start date,Start time,End date,End time\n", want: "toggl"},
+		{name: "other csv", path: "log.csv", data: "When,Minutes,Task\n", want: "csv"},
+		{name: "unreadable csv", path: "log.csv", data: "a,\"b\n", want: "csv"},
+		{name: "empty", path: "log.csv", data: "", want: "csv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectImportFormat(tt.path, []byte(tt.data)); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestImportRoundTrip(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.Local)
+	sessions := []pomodoro.SessionResult{
+		{
+			ID:              7,
+			Name:            "draft, \"chapter\" 2",
+			Profile:         "deep",
+			Project:         "book",
+			Tags:            []string{"writing", "draft"},
+			Note:            "line one\nline two",
+			SessionType:     pomodoro.Focus,
+			Status:          pomodoro.Completed,
+			Duration:        1500,
+			PlannedDuration: 1500,
+			PausedDuration:  120,
+			StartedAt:       start,
+			CompletedAt:     start.Add(27 * time.Minute),
+			Pauses: []pomodoro.Pause{
+				{StartedAt: start.Add(10 * time.Minute), EndedAt: start.Add(12 * time.Minute)},
+			},
+			Interruptions: []pomodoro.Interruption{
+				{Kind: pomodoro.External, At: start.Add(10 * time.Minute), Note: "phone"},
+				{Kind: pomodoro.Internal, At: start.Add(20 * time.Minute)},
+			},
+		},
+		{
+			ID:              8,
+			SessionType:     pomodoro.ShortBreak,
+			Status:          pomodoro.Skipped,
+			Duration:        60,
+			PlannedDuration: 300,
+			IdleBefore:      45,
+			StartedAt:       start.Add(28 * time.Minute),
+			CompletedAt:     start.Add(29 * time.Minute),
+		},
+	}
+
+	tests := []struct {
+		format string
+		read   func([]byte) ([]pomodoro.SessionResult, error)
+		// CSV rows have no room for pauses and interruptions.
+		details bool
+	}{
+		{format: "csv", read: readPomCSV},
+		{format: "json", read: readPomJSON, details: true},
+		{format: "ndjson", read: readPomNDJSON, details: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := newSessionWriter(tt.format, &buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range sessions {
+				if err := w.Write(s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			format := tt.format
+			if format == "csv" {
+				format = "pom"
+			}
+			if got := detectImportFormat("export", buf.Bytes()); got != format {
+				t.Errorf("detected %s, want %s", got, format)
+			}
+
+			got, err := tt.read(buf.Bytes())
+			if err != nil {
+				t.Fatalf("read: %v\n%s", err, buf.String())
+			}
+			if len(got) != len(sessions) {
+				t.Fatalf("read %d sessions, want %d", len(got), len(sessions))
+			}
+			for i, s := range sessions {
+				want := s
+				want.ID = 0
+				if !tt.details {
+					want.Pauses, want.Interruptions = nil, nil
+				}
+				if g, w := newExportRecord(got[i]), newExportRecord(want); !reflect.DeepEqual(g, w) {
+					t.Errorf("session %d:\n got %+v\nwant %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestReadTogglCSV(t *testing.T) {
+	data := "User,Project,Description,Tags,Start date,Start time,End date,End time,Duration\n" +
+		"me,book,Chapter 2,\"writing, draft\",2026-10-16,23:40:00,2026-10-17,00:05:30,00:25:30\n" +
+		"me,,Email,,2026-10-17,08:00:00,2026-10-17,08:00:45,00:00:45\n"
+
+	got, err := readTogglCSV([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name, project string
+		tags          []string
+		start         time.Time
+		secs          int
+	}{
+		{"Chapter 2", "book", []string{"writing", "draft"}, time.Date(2026, 10, 16, 23, 40, 0, 0, time.Local), 1530},
+		{"Email", "", nil, time.Date(2026, 10, 17, 8, 0, 0, 0, time.Local), 45},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d sessions, want %d", len(got), len(want))
+	}
+	for i, w := range want {
+		s := got[i]
+		if s.Name != w.name || s.Project != w.project || !slices.Equal(s.Tags, w.tags) {
+			t.Errorf("session %d: got %q, %q, %q; want %q, %q, %q", i, s.Name, s.Project, s.Tags, w.name, w.project, w.tags)
+		}
+		if !s.StartedAt.Equal(w.start) || s.Duration != w.secs || s.PlannedDuration != w.secs {
+			t.Errorf("session %d: got %s for %ds, want %s for %ds", i, s.StartedAt, s.Duration, w.start, w.secs)
+		}
+		if s.SessionType != pomodoro.Focus || s.Status != pomodoro.Completed {
+			t.Errorf("session %d: got %s %s, want completed focus", i, s.Status, s.SessionType)
+		}
+	}
+
+	for _, bad := range []string{
+		"Start date,Start time,End date\n",
+		"Start date,Start time,End date,End time\n16/10/2026,09:00:00,2026-10-16,09:25:00\n",
+		"Start date,Start time,End date,End time\n2026-10-16,09:00:00,2026-10-16,08:25:00\n",
+	} {
+		if _, err := readTogglCSV([]byte(bad)); err == nil {
+			t.Errorf("read %q: got no error", bad)
+		}
+	}
+}
+
+// setImportFlags sets the import command's flag variables for the rest of
+// the test.
+func setImportFlags(t *testing.T, set func()) {
+	t.Helper()
+	saved := []string{impStartCol, impEndCol, impDurCol, impNameCol, impTypeCol, impTagsCol, impLayout, impType}
+	t.Cleanup(func() {
+		impStartCol, impEndCol, impDurCol, impNameCol, impTypeCol, impTagsCol, impLayout, impType =
+			saved[0], saved[1], saved[2], saved[3], saved[4], saved[5], saved[6], saved[7]
+	})
+	impStartCol, impEndCol, impDurCol, impNameCol, impTypeCol, impTagsCol = "start", "", "", "", "", ""
+	impLayout, impType = "2006-01-02 15:04", "focus"
+	set()
+}
+
+func TestReadGenericCSV(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 16, h, m, 0, 0, time.Local) }
+	type session struct {
+		Name  string
+		Type  pomodoro.SessionType
+		Start time.Time
+		Secs  int
+		Tags  string
+	}
+
+	tests := []struct {
+		name  string
+		flags func()
+		data  string
+		want  []session
+		err   bool
+	}{
+		{
+			name:  "end column",
+			flags: func() { impStartCol, impEndCol, impNameCol = "From", "To", "Task" },
+			data:  "From,To,Task\n2026-10-16 09:00,2026-10-16 09:25,Report\n",
+			want:  []session{{"Report", pomodoro.Focus, at(9, 0), 1500, ""}},
+		},
+		{
+			name: "duration column in minutes, clock time and Go syntax",
+			flags: func() {
+				impStartCol, impDurCol, impTypeCol, impTagsCol = "When", "Minutes", "Kind", "Labels"
+				impLayout = "02/01/2006 15:04"
+			},
+			data: "When,Minutes,Kind,Labels\n" +
+				"16/10/2026 09:00,25,,\"a, b\"\n" +
+				"16/10/2026 09:30,0:05:00,short-break,\n" +
+				"16/10/2026 10:00,1h30m,Long Break,a\n" +
+				"2026-10-16T11:00:00+00:00,2.5,reading,\n",
+			want: []session{
+				{"", pomodoro.Focus, at(9, 0), 1500, "a,b"},
+				{"", pomodoro.ShortBreak, at(9, 30), 300, ""},
+				{"", pomodoro.LongBreak, at(10, 0), 5400, "a"},
+				{"", "reading", time.Date(2026, 10, 16, 11, 0, 0, 0, time.UTC), 150, ""},
+			},
+		},
+		{
+			name:  "default type",
+			flags: func() { impDurCol, impType = "mins", "flow" },
+			data:  "start,mins\n2026-10-16 09:00,50\n",
+			want:  []session{{"", pomodoro.Flow, at(9, 0), 3000, ""}},
+		},
+		{
+			name:  "header lacks a mapped column",
+			flags: func() { impEndCol, impNameCol = "end", "Task" },
+			data:  "start,end\n2026-10-16 09:00,2026-10-16 09:25\n",
+			err:   true,
+		},
+		{
+			name:  "both end and duration",
+			flags: func() { impEndCol, impDurCol = "end", "mins" },
+			data:  "start,end,mins\n",
+			err:   true,
+		},
+		{
+			name:  "neither end nor duration",
+			flags: func() {},
+			data:  "start\n",
+			err:   true,
+		},
+		{
+			name:  "bad duration",
+			flags: func() { impDurCol = "mins" },
+			data:  "start,mins\n2026-10-16 09:00,1:xx\n",
+			err:   true,
+		},
+		{
+			name:  "bad --type",
+			flags: func() { impDurCol, impType = "mins", "nap" },
+			data:  "start,mins\n2026-10-16 09:00,20\n",
+			err:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setImportFlags(t, tt.flags)
+			got, err := readGenericCSV([]byte(tt.data))
+			if tt.err {
+				if err == nil {
+					t.Fatalf("got %d sessions, want an error", len(got))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d sessions, want %d", len(got), len(tt.want))
+			}
+			for i, w := range tt.want {
+				s := got[i]
+				g := session{s.Name, s.SessionType, s.StartedAt, s.Duration, ""}
+				for j, tag := range s.Tags {
+					if j > 0 {
+						g.Tags += ","
+					}
+					g.Tags += tag
+				}
+				if !g.Start.Equal(w.Start) {
+					t.Errorf("session %d: start %s, want %s", i, g.Start, w.Start)
+				}
+				g.Start = w.Start
+				if g != w {
+					t.Errorf("session %d:\n got %+v\nwant %+v", i, g, w)
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+		err  bool
+	}{
+		{in: "25", want: 25 * time.Minute},
+		{in: "0.5", want: 30 * time.Second},
+		{in: "25:00", want: 25 * time.Minute},
+		{in: "1:02:03", want: time.Hour + 2*time.Minute + 3*time.Second},
+		{in: "1h5m", want: 65 * time.Minute},
+		{in: "1:2:3:4", err: true},
+		{in: "a:00", err: true},
+		{in: "", err: true},
+	}
+	for _, tt := range tests {
+		got, err := parseImportDuration(tt.in)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("%q: got %s, %v; want %s, error %t", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestPlanImport(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	session := func(min int, typ pomodoro.SessionType, project string) pomodoro.SessionResult {
+		s, err := importedSession(start.Add(time.Duration(min)*time.Minute), start.Add(time.Duration(min+5)*time.Minute), typ)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.Project = project
+		return s
+	}
+
+	if err := store.CreateProject(ctx, storage.Project{Name: "book", CreatedAt: start}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SaveSession(ctx, session(0, pomodoro.Focus, "book")); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions := []pomodoro.SessionResult{
+		session(0, pomodoro.Focus, ""),            // already saved
+		session(0, pomodoro.ShortBreak, ""),       // same start, other type
+		session(10, pomodoro.Focus, "book"),       // existing project
+		session(20, pomodoro.Focus, "garden"),     // new project
+		session(20, pomodoro.Focus, "garden"),     // repeated in the file
+		session(30, pomodoro.LongBreak, "garden"), // new project again
+		session(40, pomodoro.Focus, "taxes"),      // another new project
+	}
+
+	plan, err := planImport(ctx, store, sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan.fresh) != 5 || plan.skipped != 2 {
+		t.Errorf("got %d fresh, %d skipped; want 5 fresh, 2 skipped", len(plan.fresh), plan.skipped)
+	}
+	if want := []string{"garden", "taxes"}; !slices.Equal(plan.projects, want) {
+		t.Errorf("projects: got %q, want %q", plan.projects, want)
+	}
+	if got, want := projectList(", and create projects", plan.projects), `, and create projects "garden", "taxes"`; got != want {
+		t.Errorf("summary: got %s, want %s", got, want)
+	}
+
+	// A dry run stops at the plan, so the store must be untouched.
+	if all, err := store.ListSessions(ctx, storage.QueryFilter{}); err != nil || len(all) != 1 {
+		t.Fatalf("planning saved sessions: got %d, %v", len(all), err)
+	}
+
+	created, err := store.ImportSessions(ctx, plan.fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(created, plan.projects) {
+		t.Errorf("created %q, planned %q", created, plan.projects)
+	}
+
+	// Importing the same file again finds only duplicates.
+	again, err := planImport(ctx, store, sessions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again.fresh) != 0 || again.skipped != len(sessions) || len(again.projects) != 0 {
+		t.Errorf("second import: got %d fresh, %d skipped, projects %q; want all skipped",
+			len(again.fresh), again.skipped, again.projects)
+	}
+}
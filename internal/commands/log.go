@@ -0,0 +1,153 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+var logCmd = &cobra.Command{
+	Use:   "log",
+	Short: "Record a session that happened away from the timer",
+	Example: `  pom log --start "2026-10-17 09:00" --duration 25m --name "reading"
+  pom log --start "2026-10-17 13:00" --end "2026-10-17 13:15" --type short-break`,
+	Args: cobra.NoArgs,
+	RunE: runLog,
+}
+
+const logTimeLayout = "2006-01-02 15:04"
+
+var (
+	logStart    string
+	logEnd      string
+	logDuration string
+	logType     string
+	logName     string
+	logTags     []string
+	logProject  string
+	logNote     string
+)
+
+func init() {
+	logCmd.Flags().StringVar(&logStart, "start", "", "when the session started (YYYY-MM-DD HH:MM, local time)")
+	logCmd.Flags().StringVar(&logEnd, "end", "", "when the session ended (YYYY-MM-DD HH:MM, local time)")
+	logCmd.Flags().StringVar(&logDuration, "duration", "", "how long the session lasted, e.g. 25m")
+	logCmd.Flags().StringVar(&logType, "type", "focus", "session type: focus, flow, short-break, long-break")
+	logCmd.Flags().StringVarP(&logName, "name", "n", "", "optional session label")
+	logCmd.Flags().StringArrayVarP(&logTags, "tag", "t", nil, "tag the session (repeatable)")
+	logCmd.Flags().StringVar(&logProject, "project", "", "log the session against a project")
+	logCmd.Flags().StringVar(&logNote, "note", "", "what you got done")
+
+	logCmd.MarkFlagRequired("start")
+	logCmd.MarkFlagsOneRequired("end", "duration")
+	logCmd.MarkFlagsMutuallyExclusive("end", "duration")
+
+	rootCmd.AddCommand(logCmd)
+}
+
+func runLog(cmd *cobra.Command, args []string) error {
+	start, end, err := parseLogSpan(logStart, logEnd, logDuration, time.Now())
+	if err != nil {
+		return err
+	}
+
+	st, ok := parseSessionType(logType)
+	if !ok {
+		return fmt.Errorf("invalid --type %q: want focus, flow, short-break or long-break", logType)
+	}
+
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	overlaps, err := findOverlaps(ctx, store, start, end)
+	if err != nil {
+		return fmt.Errorf("check for overlaps: %w", err)
+	}
+	for _, s := range overlaps {
+		fmt.Fprintf(os.Stderr, "Warning: overlaps %s from %s to %s\n",
+			s.SessionType, s.StartedAt.Local().Format(logTimeLayout), s.CompletedAt.Local().Format(logTimeLayout))
+	}
+
+	secs := int(end.Sub(start).Round(time.Second).Seconds())
+	sr := pomodoro.SessionResult{
+		Name:            logName,
+		Project:         logProject,
+		Tags:            normalizeTags(logTags),
+		Note:            logNote,
+		SessionType:     st,
+		Status:          pomodoro.Completed,
+		Duration:        secs,
+		PlannedDuration: secs,
+		StartedAt:       start,
+		CompletedAt:     end,
+	}
+	if err := store.SaveSession(ctx, sr); err != nil {
+		return fmt.Errorf("save session: %w", err)
+	}
+	fmt.Printf("Logged %s of %s starting %s.\n",
+		pomodoro.FormatDuration(end.Sub(start)), st, start.Format(logTimeLayout))
+	return nil
+}
+
+// parseLogSpan parses the --start, --end and --duration values, exactly one
+// of the last two set, into the span of a session that ended by now.
+func parseLogSpan(startArg, endArg, durationArg string, now time.Time) (start, end time.Time, err error) {
+	start, err = time.ParseInLocation(logTimeLayout, startArg, time.Local)
+	if err != nil {
+		return start, end, fmt.Errorf("invalid --start time: %w", err)
+	}
+
+	if endArg != "" {
+		end, err = time.ParseInLocation(logTimeLayout, endArg, time.Local)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --end time: %w", err)
+		}
+	} else {
+		d, err := time.ParseDuration(durationArg)
+		if err != nil {
+			return start, end, fmt.Errorf("invalid --duration: %w", err)
+		}
+		end = start.Add(d)
+	}
+	if !end.After(start) {
+		return start, end, fmt.Errorf("session must end after it starts")
+	}
+	if end.After(now) {
+		return start, end, fmt.Errorf("session ends in the future (%s)", end.Format(logTimeLayout))
+	}
+	return start, end, nil
+}
+
+// findOverlaps returns the recorded sessions that share any time with
+// [start, end). Sessions are fetched a day either side of the range, which
+// covers any session the timer would realistically record.
+func findOverlaps(ctx context.Context, store storage.Store, start, end time.Time) ([]pomodoro.SessionResult, error) {
+	from, to := start.Add(-24*time.Hour), end.Add(24*time.Hour)
+	sessions, err := store.ListSessions(ctx, storage.QueryFilter{From: &from, To: &to})
+	if err != nil {
+		return nil, err
+	}
+
+	var overlaps []pomodoro.SessionResult
+	for _, s := range sessions {
+		if s.StartedAt.Before(end) && s.CompletedAt.After(start) {
+			overlaps = append(overlaps, s)
+		}
+	}
+	return overlaps, nil
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+func TestParseLogSpan(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 10, 17, h, m, 0, 0, time.Local) }
+	now := at(12, 0)
+
+	tests := []struct {
+		name               string
+		start, end, dur    string
+		wantStart, wantEnd time.Time
+		err                string // prefix of the error, if one is expected
+	}{
+		{name: "end", start: "2026-10-17 09:00", end: "2026-10-17 09:25", wantStart: at(9, 0), wantEnd: at(9, 25)},
+		{name: "duration", start: "2026-10-17 09:00", dur: "1h30m", wantStart: at(9, 0), wantEnd: at(10, 30)},
+		{name: "ends now", start: "2026-10-17 11:00", end: "2026-10-17 12:00", wantStart: at(11, 0), wantEnd: at(12, 0)},
+		{name: "end before start", start: "2026-10-17 09:00", end: "2026-10-17 08:55", err: "session must end after it starts"},
+		{name: "end equals start", start: "2026-10-17 09:00", end: "2026-10-17 09:00", err: "session must end after it starts"},
+		{name: "negative duration", start: "2026-10-17 09:00", dur: "-25m", err: "session must end after it starts"},
+		{name: "end in the future", start: "2026-10-17 11:50", end: "2026-10-17 12:01", err: "session ends in the future (2026-10-17 12:01)"},
+		{name: "duration into the future", start: "2026-10-17 11:50", dur: "25m", err: "session ends in the future (2026-10-17 12:15)"},
+		{name: "bad start", start: "17/10/2026 09:00", dur: "25m", err: "invalid --start time: "},
+		{name: "bad end", start: "2026-10-17 09:00", end: "09:25", err: "invalid --end time: "},
+		{name: "bad duration", start: "2026-10-17 09:00", dur: "25", err: "invalid --duration: "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseLogSpan(tt.start, tt.end, tt.dur, now)
+			if tt.err != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one starting %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got %s to %s, want %s to %s", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	at := func(d, h, m int) time.Time { return time.Date(2026, 10, d, h, m, 0, 0, time.UTC) }
+	recorded := []struct {
+		name       string
+		start, end time.Time
+	}{
+		{"morning", at(17, 9, 0), at(17, 9, 25)},
+		{"break", at(17, 9, 30), at(17, 9, 35)},
+		{"late", at(17, 10, 0), at(17, 10, 25)},
+		{"overnight", at(16, 23, 30), at(17, 0, 30)},
+	}
+	for _, r := range recorded {
+		secs := int(r.end.Sub(r.start).Seconds())
+		err := store.SaveSession(ctx, pomodoro.SessionResult{
+			Name: r.name, SessionType: pomodoro.Focus, Status: pomodoro.Completed,
+			Duration: secs, PlannedDuration: secs, StartedAt: r.start, CompletedAt: r.end,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		start, end time.Time
+		want       []string // newest first
+	}{
+		{"inside one", at(17, 9, 10), at(17, 9, 20), []string{"morning"}},
+		{"covering one", at(17, 8, 50), at(17, 9, 28), []string{"morning"}},
+		{"touching edges", at(17, 9, 25), at(17, 9, 30), nil},
+		{"across several", at(17, 9, 20), at(17, 10, 5), []string{"late", "break", "morning"}},
+		{"across midnight", at(17, 0, 0), at(17, 0, 10), []string{"overnight"}},
+		{"gap", at(17, 10, 30), at(17, 11, 0), nil},
+		{"another day", at(18, 9, 0), at(18, 9, 25), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			overlaps, err := findOverlaps(ctx, store, tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, s := range overlaps {
+				got = append(got, s.Name)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
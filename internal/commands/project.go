@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Manage projects and their weekly focus goals",
+}
+
+var projectAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Create a project",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectAdd,
+}
+
+var projectListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List projects",
+	Args:  cobra.NoArgs,
+	RunE:  runProjectList,
+}
+
+var projectArchiveCmd = &cobra.Command{
+	Use:   "archive <name>",
+	Short: "Archive a project so new sessions can no longer use it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runProjectArchive,
+}
+
+var (
+	projColor   string
+	projGoal    string
+	projProfile string
+	projAll     bool
+	projJSON    bool
+)
+
+func init() {
+	projectAddCmd.Flags().StringVar(&projColor, "color", "", "display colour (ANSI number or hex)")
+	projectAddCmd.Flags().StringVar(&projGoal, "goal", "", "weekly focus goal, e.g. 10h")
+	projectAddCmd.Flags().StringVar(&projProfile, "profile", "", "default timing profile for the project's sessions")
+
+	projectListCmd.Flags().BoolVar(&projAll, "all", false, "include archived projects")
+	projectListCmd.Flags().BoolVar(&projJSON, "json", false, "output as JSON")
+
+	projectCmd.AddCommand(projectAddCmd, projectListCmd, projectArchiveCmd)
+	rootCmd.AddCommand(projectCmd)
+}
+
+func runProjectAdd(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+
+	p := storage.Project{
+		Name:      args[0],
+		Color:     projColor,
+		Profile:   projProfile,
+		CreatedAt: time.Now(),
+	}
+	if projGoal != "" {
+		goal, err := time.ParseDuration(projGoal)
+		if err != nil {
+			return fmt.Errorf("invalid --goal duration: %w", err)
+		}
+		p.WeeklyGoal = goal
+	}
+	if p.Profile != "" {
+		if _, ok := cfg.LookupProfile(p.Profile); !ok {
+			return fmt.Errorf("unknown profile %q", p.Profile)
+		}
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.CreateProject(context.Background(), p); err != nil {
+		return err
+	}
+	fmt.Printf("Created project %q.\n", p.Name)
+	return nil
+}
+
+func runProjectList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	projects, err := store.ListProjects(ctx, projAll)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+
+	if projJSON {
+		data, err := json.MarshalIndent(projects, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(projects) == 0 {
+		fmt.Println("No projects yet. Create one with `pom project add <name>`.")
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
+	fmt.Println(headerStyle.Render("📁 Projects"))
+	fmt.Println()
+
+	weekStart := startOfWeek(time.Now())
+	var rows [][]string
+	for _, p := range projects {
+		stats, err := store.GetStatistics(ctx, storage.QueryFilter{Project: p.Name, From: &weekStart})
+		if err != nil {
+			return fmt.Errorf("query statistics: %w", err)
+		}
+
+		name := lipgloss.NewStyle().Foreground(lipgloss.Color(p.Color)).Render(p.Name)
+		status := "active"
+		if p.ArchivedAt != nil {
+			status = "archived"
+		}
+		rows = append(rows, []string{
+			name,
+			p.Profile,
+			formatGoal(stats.FocusTime+stats.FlowTime, p.WeeklyGoal),
+			status,
+		})
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Theme.Border))).
+		Headers("Project", "Profile", "This Week", "Status").
+		Rows(rows...)
+
+	fmt.Println(t)
+
+	return nil
+}
+
+func runProjectArchive(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := store.ArchiveProject(context.Background(), args[0], time.Now()); err != nil {
+		return err
+	}
+	fmt.Printf("Archived project %q.\n", args[0])
+	return nil
+}
+
+// startOfWeek returns local midnight on the Monday of t's week.
+func startOfWeek(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	y, m, d := t.AddDate(0, 0, -offset).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// formatGoal renders focus time against a goal, e.g. "6h 15m 0s / 10h (62%)".
+func formatGoal(done, goal time.Duration) string {
+	if goal <= 0 {
+		return pomodoro.FormatDuration(done)
+	}
+	return fmt.Sprintf("%s / %s (%.0f%%)", pomodoro.FormatDuration(done), pomodoro.FormatDuration(goal), 100*done.Hours()/goal.Hours())
+}
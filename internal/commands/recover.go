@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+// Ways to deal with an interval left unfinished by a timer that died, given
+// with --recover or chosen at a prompt.
+const (
+	recoverResume   = "resume"
+	recoverAbort    = "abort"
+	recoverComplete = "complete"
+)
+
+// chooseRecovery decides what to do with an interval left unfinished by a
+// timer that died: action if it is set, or else the answer to a prompt if
+// interactive is set. It returns "" if there is no such interval. Nothing
+// is changed; that is up to recoverInterval.
+func chooseRecovery(store storage.Store, action string, interactive bool) (string, error) {
+	cp, err := store.LoadCheckpoint(context.Background())
+	if errors.Is(err, storage.ErrNoCheckpoint) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	now := time.Now()
+	if action == "" {
+		if !interactive {
+			return "", errors.New("an interval was left unfinished; pass --recover resume, abort or complete")
+		}
+		return promptRecovery(*cp, now)
+	}
+	if _, err := recoveryStatus(*cp, action, now); err != nil {
+		return "", err
+	}
+	return action, nil
+}
+
+// recoverInterval carries out action, as returned by chooseRecovery, on the
+// interval left unfinished by a timer that died. Aborted and completed
+// intervals are recorded and the checkpoint is cleared; the checkpoint of a
+// resumed one is returned. Only the process serving the timer socket may
+// call it: the checkpoint of a live timer would otherwise be recorded twice.
+func recoverInterval(store storage.Store, action string) (*pomodoro.Checkpoint, error) {
+	ctx := context.Background()
+	cp, err := store.LoadCheckpoint(ctx)
+	if errors.Is(err, storage.ErrNoCheckpoint) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load checkpoint: %w", err)
+	}
+	if action == "" {
+		return nil, errors.New("an interval was left unfinished; pass --recover resume, abort or complete")
+	}
+
+	status, err := recoveryStatus(*cp, action, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if action == recoverResume {
+		return cp, nil
+	}
+	if sr, ok := cp.Result(status); ok {
+		if err := store.SaveSession(ctx, sr); err != nil {
+			return nil, fmt.Errorf("save session: %w", err)
+		}
+		fmt.Printf("Recorded the unfinished %s as %s.\n", sr.SessionType, status)
+	}
+	if err := store.ClearCheckpoint(ctx); err != nil {
+		return nil, fmt.Errorf("clear checkpoint: %w", err)
+	}
+	return nil, nil
+}
+
+// recoveryStatus returns the status action records cp with, or an error if
+// action is not one of the ways to recover it at now.
+func recoveryStatus(cp pomodoro.Checkpoint, action string, now time.Time) (pomodoro.SessionStatus, error) {
+	switch action {
+	case recoverResume, recoverAbort:
+		return pomodoro.Aborted, nil
+	case recoverComplete:
+		if !cp.Due(now) {
+			return "", fmt.Errorf("the unfinished %s has not reached its end; resume or abort it", cp.Cycle.Current)
+		}
+		return pomodoro.Completed, nil
+	}
+	return "", fmt.Errorf("invalid --recover %q: want resume, abort or complete", action)
+}
+
+// resumeConfig returns the config to resume cp with. The run cp was started
+// with is applied over cfg, the config file as loaded for this start, and
+// any timer flags given this time apply over that.
+func resumeConfig(cmd *cobra.Command, cfg config.Config, cp pomodoro.Checkpoint) (config.Config, error) {
+	cp.Run.Apply(&cfg)
+	if cmd.Flags().Changed("profile") {
+		if err := cfg.ApplyProfile(flagProfile); err != nil {
+			return cfg, err
+		}
+	}
+	if err := applyStartFlags(cmd, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// promptRecovery describes cp and asks what to do with it. Completing is
+// only offered once the interval's end has passed.
+func promptRecovery(cp pomodoro.Checkpoint, now time.Time) (string, error) {
+	const layout = "Mon 15:04"
+	what := string(cp.Cycle.Current)
+	if name := cp.Run.Name; name != "" {
+		what += " - " + name
+	}
+	if cp.Waiting {
+		fmt.Printf("A %s was waiting to start when pom last ran (%s).\n", what, cp.SavedAt.Local().Format(layout))
+	} else {
+		done := formatClock(cp.Elapsed())
+		if cp.Cycle.Current != pomodoro.Flow {
+			done += " of " + formatClock(cp.Total)
+		}
+		fmt.Printf("A %s started %s was left unfinished when pom last ran (%s), with %s done.\n",
+			what, cp.StartedAt.Local().Format(layout), cp.SavedAt.Local().Format(layout), done)
+	}
+
+	choices := map[string]string{"r": recoverResume, "a": recoverAbort}
+	question := "[r] resume it • [a] record it as aborted"
+	if cp.Due(now) {
+		choices["c"] = recoverComplete
+		question += " • [c] count it as completed"
+	}
+
+	in := bufio.NewReader(os.Stdin)
+	for {
+		fmt.Print(question + ": ")
+		line, err := in.ReadString('\n')
+		if action, ok := choices[strings.ToLower(strings.TrimSpace(line))]; ok {
+			return action, nil
+		}
+		if errors.Is(err, io.EOF) {
+			fmt.Println()
+			return "", errors.New("an interval was left unfinished; pass --recover resume, abort or complete")
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
@@ -3,8 +3,12 @@ package commands
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/storage"
 )
 
 var rootCmd = &cobra.Command{
@@ -18,3 +22,24 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// openStore opens the session database at cfg.DBPath, or at the default
+// location when none is configured.
+func openStore(cfg config.Config) (*storage.SQLiteStore, error) {
+	dbPath := cfg.DBPath
+	if dbPath == "" {
+		p, err := storage.DefaultDBPath()
+		if err != nil {
+			return nil, fmt.Errorf("determine database path: %w", err)
+		}
+		dbPath = p
+	} else if err := os.MkdirAll(filepath.Dir(dbPath), 0o755); err != nil {
+		return nil, fmt.Errorf("create database directory: %w", err)
+	}
+
+	store, err := storage.NewSQLiteStore(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	return store, nil
+}
@@ -1,17 +1,18 @@
 package commands
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"log"
+	"slices"
+	"strings"
 	"time"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/daemon"
 	"github.com/zjom/pom/internal/storage"
-	"github.com/zjom/pom/internal/tui"
 )
 
 var startCmd = &cobra.Command{
@@ -22,82 +23,191 @@ var startCmd = &cobra.Command{
 
 var (
 	flagName    string
+	flagTags    []string
+	flagProfile string
+	flagProject string
 	flagSession string
 	flagSBreak  string
 	flagLBreak  string
 	flagNBreak  int
+	flagFlow    bool
+	flagSeq     string
+	flagNote    bool
+	flagDetach  bool
+	flagRecover string
 )
 
 func init() {
-	startCmd.Flags().StringVarP(&flagName, "name", "n", "", "optional session label")
-	startCmd.Flags().StringVarP(&flagSession, "session", "s", "25m", "focus duration")
-	startCmd.Flags().StringVar(&flagSBreak, "sbreak", "5m", "short break duration")
-	startCmd.Flags().StringVar(&flagLBreak, "lbreak", "15m", "long break duration")
-	startCmd.Flags().IntVar(&flagNBreak, "nbreak", 4, "sessions before a long break")
+	registerStartFlags(startCmd.Flags())
+	startCmd.Flags().BoolVarP(&flagDetach, "detach", "d", false, "run the timer in the background without attaching to it (see pom attach)")
 
 	rootCmd.AddCommand(startCmd)
 }
 
+// registerStartFlags adds the flags that configure a timer, shared by
+// `pom start` and `pom daemon`.
+func registerStartFlags(fs *pflag.FlagSet) {
+	fs.StringVarP(&flagName, "name", "n", "", "optional session label")
+	fs.StringArrayVarP(&flagTags, "tag", "t", nil, "tag the session (repeatable)")
+	fs.StringVarP(&flagProfile, "profile", "P", "", "timing profile, e.g. classic, deep, sprint")
+	fs.StringVar(&flagProject, "project", "", "log sessions against a project")
+	fs.StringVarP(&flagSession, "session", "s", "", "focus duration (default 25m)")
+	fs.StringVar(&flagSBreak, "sbreak", "", "short break duration (default 5m)")
+	fs.StringVar(&flagLBreak, "lbreak", "", "long break duration (default 15m)")
+	fs.IntVar(&flagNBreak, "nbreak", 0, "sessions before a long break (default 4)")
+	fs.BoolVar(&flagFlow, "flow", false, "flowtime mode: focus counts up until you end it")
+	fs.StringVar(&flagSeq, "sequence", "", "run a [sequences.<name>] interval sequence from the config file")
+	fs.BoolVar(&flagNote, "note-prompt", false, "ask what you got done at the end of each focus session")
+	fs.StringVar(&flagRecover, "recover", "", "what to do with an interval left unfinished by a crash: resume, abort, complete")
+}
+
 func runStart(cmd *cobra.Command, args []string) error {
-	sessionDur, err := time.ParseDuration(flagSession)
+	if _, err := daemon.Call(daemon.CmdStatus); err == nil {
+		// The running timer keeps its settings, so flags for a new one
+		// would be silently lost.
+		var ignored []string
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			if f.Name != "detach" {
+				ignored = append(ignored, "--"+f.Name)
+			}
+		})
+		if len(ignored) > 0 {
+			return fmt.Errorf("a timer is already running, so %s would have no effect; stop it first with pom stop",
+				strings.Join(ignored, ", "))
+		}
+		if flagDetach {
+			fmt.Println("A timer is already running; see pom status.")
+			return nil
+		}
+		fmt.Println("A timer is already running; attaching to it.")
+		return runAttach(cmd, args)
+	}
+
+	// The timer runs in the daemon. Its settings are checked, and any
+	// interval a crashed timer left behind is dealt with, here first, where
+	// mistakes can be reported and questions asked.
+	_, store, _, err := setupTimer(cmd)
 	if err != nil {
-		return fmt.Errorf("invalid session duration: %w", err)
+		return err
 	}
-	shortDur, err := time.ParseDuration(flagSBreak)
+	action, err := chooseRecovery(store, flagRecover, true)
+	store.Close()
 	if err != nil {
-		return fmt.Errorf("invalid short break duration: %w", err)
+		return err
 	}
-	longDur, err := time.ParseDuration(flagLBreak)
+	if action != "" {
+		cmd.Flags().Set("recover", action)
+	}
+
+	pid, err := spawnDaemon(cmd)
 	if err != nil {
-		return fmt.Errorf("invalid long break duration: %w", err)
+		return err
 	}
+	if flagDetach {
+		fmt.Printf("Timer running in the background (pid %d). Use `pom attach` to watch it, `pom stop` to end it.\n", pid)
+		return nil
+	}
+	return runAttach(cmd, args)
+}
 
-	cfg := config.Config{
-		SessionName:     flagName,
-		SessionDuration: sessionDur,
-		ShortBreak:      shortDur,
-		LongBreak:       longDur,
-		SessionsToLong:  flagNBreak,
+// setupTimer loads the configuration for a new timer from the config file
+// and start flags, and opens the store. If the timer is for a project, the
+// project must be active, and its default profile applies only when no
+// profile was chosen by --profile, POM_PROFILE or the config file.
+func setupTimer(cmd *cobra.Command) (config.Config, *storage.SQLiteStore, *storage.Project, error) {
+	cfg, err := config.Load(flagProfile)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+	if err := applyStartFlags(cmd, &cfg); err != nil {
+		return cfg, nil, nil, err
 	}
 
-	dbPath, err := storage.DefaultDBPath()
+	store, err := openStore(cfg)
 	if err != nil {
-		return fmt.Errorf("determine database path: %w", err)
+		return cfg, nil, nil, err
+	}
+	if cfg.Project == "" {
+		return cfg, store, nil, nil
 	}
 
-	store, err := storage.NewSQLiteStore(dbPath)
+	project, err := store.GetProject(context.Background(), cfg.Project)
+	if err == nil && project.ArchivedAt != nil {
+		err = fmt.Errorf("project %q is archived", project.Name)
+	}
+	if err == nil && cfg.Profile == "" && project.Profile != "" {
+		cfg, err = config.Load(project.Profile)
+		if err == nil {
+			err = applyStartFlags(cmd, &cfg)
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("open database: %w", err)
+		store.Close()
+		return cfg, nil, nil, err
 	}
-	defer store.Close()
+	return cfg, store, project, nil
+}
 
-	m := tui.NewModel(cfg, store)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+// applyStartFlags overrides cfg with any timing flags set on the command line.
+func applyStartFlags(cmd *cobra.Command, cfg *config.Config) error {
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		cfg.SessionName = flagName
+	}
+	if flags.Changed("project") {
+		cfg.Project = flagProject
+	}
+	if flags.Changed("tag") {
+		cfg.Tags = flagTags
+	}
+	cfg.Tags = normalizeTags(cfg.Tags)
 
-	finalState, err := p.Run()
-	if err != nil {
-		return fmt.Errorf("run timer: %w", err)
-	}
-
-	if fm, ok := finalState.(tui.Model); ok {
-		result := struct {
-			Name              string    `json:"name,omitempty"`
-			CompletedSessions int       `json:"completedSessions"`
-			StartTime         time.Time `json:"startTime"`
-			EndTime           time.Time `json:"endTime"`
-		}{
-			Name:              fm.Cfg.SessionName,
-			CompletedSessions: fm.SessionsDone,
-			StartTime:         fm.StartTime,
-			EndTime:           time.Now(),
+	durations := []struct {
+		flag string
+		val  string
+		dst  *time.Duration
+	}{
+		{"session", flagSession, &cfg.SessionDuration},
+		{"sbreak", flagSBreak, &cfg.ShortBreak},
+		{"lbreak", flagLBreak, &cfg.LongBreak},
+	}
+	for _, d := range durations {
+		if !flags.Changed(d.flag) {
+			continue
 		}
-		data, err := json.MarshalIndent(result, "", "  ")
+		dur, err := time.ParseDuration(d.val)
 		if err != nil {
-			log.Printf("Failed to marshal JSON: %v", err)
-		} else {
-			fmt.Println(string(data))
+			return fmt.Errorf("invalid --%s duration: %w", d.flag, err)
 		}
+		*d.dst = dur
 	}
 
+	if flags.Changed("nbreak") {
+		cfg.SessionsToLong = flagNBreak
+	}
+	if flags.Changed("flow") {
+		cfg.FlowMode = flagFlow
+	}
+	if flags.Changed("sequence") {
+		cfg.Sequence = flagSeq
+	}
+	if flags.Changed("note-prompt") {
+		cfg.NotePrompt = flagNote
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid flags: %w", err)
+	}
 	return nil
 }
+
+// normalizeTags trims tags and drops empty and repeated ones.
+func normalizeTags(tags []string) []string {
+	var out []string
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		if t != "" && !slices.Contains(out, t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,227 @@
+package commands
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/daemon"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the running timer",
+	Long: `Show the running timer. --format takes a preset or a Go template over the
+fields Icon, State (focus, break, paused, waiting), Type, Remaining (the
+time so far in a flow session), Elapsed, Total, Percent, Name, Profile,
+Project, Tags (a list, e.g. {{join .Tags ","}}) and SessionsDone. When
+nothing is running the output is the --idle text, or the preset's idle
+equivalent.
+
+Presets:
+  plain     one line of text
+  tmux      coloured for a tmux status line
+  waybar    JSON for a waybar custom module
+  i3blocks  JSON for an i3blocks block with format=json`,
+	Example: `  pom status --format tmux
+  pom status --format '{{.Icon}} {{.Remaining}} {{.Name}}'
+  pom status --format waybar`,
+	Args: cobra.NoArgs,
+	RunE: runStatus,
+}
+
+var (
+	statusJSON   bool
+	statusFormat string
+	statusIdle   string
+)
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "print the full timer state as JSON")
+	statusCmd.Flags().StringVar(&statusFormat, "format", "", "output preset (plain, tmux, waybar, i3blocks) or Go template")
+	statusCmd.Flags().StringVar(&statusIdle, "idle", "idle", "output when no timer is running")
+	statusCmd.MarkFlagsMutuallyExclusive("json", "format")
+
+	rootCmd.AddCommand(statusCmd)
+}
+
+// statusView is the data --format templates see. Durations are formatted
+// as MM:SS.
+type statusView struct {
+	Icon         string
+	State        string
+	Type         string
+	Remaining    string
+	Elapsed      string
+	Total        string
+	Percent      int
+	Name         string
+	Profile      string
+	Project      string
+	Tags         []string
+	SessionsDone int
+}
+
+func newStatusView(s daemon.State) statusView {
+	v := statusView{
+		Icon:         "🍅",
+		State:        "focus",
+		Type:         string(s.SessionType),
+		Remaining:    formatClock(s.Remaining),
+		Elapsed:      formatClock(s.Elapsed),
+		Total:        formatClock(s.Total),
+		Name:         s.Name,
+		Profile:      s.Profile,
+		Project:      s.Project,
+		Tags:         s.Tags,
+		SessionsDone: s.SessionsDone,
+	}
+	if s.Break {
+		v.Icon, v.State = "☕", "break"
+	}
+	switch {
+	case s.Waiting:
+		v.Icon, v.State = "⏳", "waiting"
+	case s.Paused:
+		v.Icon, v.State = "⏸", "paused"
+	}
+	if s.SessionType == pomodoro.Flow {
+		v.Remaining = v.Elapsed
+	} else if s.Total > 0 {
+		v.Percent = int(100 * (s.Total - s.Remaining) / s.Total)
+	}
+	return v
+}
+
+// statusPresets are the named --format outputs. They are given nil when no
+// timer is running, and the --idle text.
+var statusPresets = map[string]func(v *statusView, idle string) (string, error){
+	"plain": func(v *statusView, idle string) (string, error) {
+		if v == nil {
+			return idle, nil
+		}
+		s := v.Type + " " + v.Remaining
+		if v.Name != "" {
+			s += " " + v.Name
+		}
+		if v.State == "paused" {
+			s += " (paused)"
+		}
+		return s, nil
+	},
+	"tmux": func(v *statusView, idle string) (string, error) {
+		if v == nil {
+			return idle, nil
+		}
+		colour := "yellow"
+		switch v.State {
+		case "focus":
+			colour = "red"
+		case "break":
+			colour = "green"
+		}
+		return fmt.Sprintf("#[fg=%s]%s %s#[default]", colour, v.Icon, v.Remaining), nil
+	},
+	"waybar": func(v *statusView, idle string) (string, error) {
+		if v == nil {
+			return jsonString(map[string]any{"text": idle, "tooltip": "No timer running", "class": "idle", "alt": "idle", "percentage": 0})
+		}
+		tooltip := v.Type
+		if v.Name != "" {
+			tooltip += " - " + v.Name
+		}
+		tooltip += fmt.Sprintf(" (%s of %s, %d done)", v.Elapsed, v.Total, v.SessionsDone)
+		return jsonString(map[string]any{
+			"text":       v.Icon + " " + v.Remaining,
+			"tooltip":    tooltip,
+			"class":      v.State,
+			"alt":        v.State,
+			"percentage": v.Percent,
+		})
+	},
+	"i3blocks": func(v *statusView, idle string) (string, error) {
+		if v == nil {
+			return jsonString(map[string]any{"full_text": idle, "short_text": idle})
+		}
+		full := v.Icon + " " + v.Remaining
+		if v.Name != "" {
+			full += " " + v.Name
+		}
+		return jsonString(map[string]any{"full_text": full, "short_text": v.Remaining})
+	},
+}
+
+func jsonString(v any) (string, error) {
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// statusRenderer returns the preset named format or, failing that, a
+// renderer for format as a template.
+func statusRenderer(format string) (func(v *statusView, idle string) (string, error), error) {
+	if render := statusPresets[format]; render != nil {
+		return render, nil
+	}
+	tmpl, err := template.New("status").Funcs(template.FuncMap{"join": strings.Join}).Parse(format)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format: %w", err)
+	}
+	return func(v *statusView, idle string) (string, error) {
+		if v == nil {
+			return idle, nil
+		}
+		var b strings.Builder
+		if err := tmpl.Execute(&b, v); err != nil {
+			return "", fmt.Errorf("render --format: %w", err)
+		}
+		return b.String(), nil
+	}, nil
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	var render func(v *statusView, idle string) (string, error)
+	if statusFormat != "" {
+		var err error
+		if render, err = statusRenderer(statusFormat); err != nil {
+			return err
+		}
+	}
+
+	s, err := daemon.Call(daemon.CmdStatus)
+	running := err == nil && !s.Stopped
+	if err != nil && !errors.Is(err, daemon.ErrNotRunning) {
+		return err
+	}
+
+	switch {
+	case statusJSON && !running:
+		fmt.Println("null")
+	case statusJSON:
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case render != nil:
+		var v *statusView
+		if running {
+			view := newStatusView(*s)
+			v = &view
+		}
+		out, err := render(v, statusIdle)
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+	case !running:
+		fmt.Println("No timer running.")
+	default:
+		fmt.Println(describeState(*s))
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/daemon"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+func TestStatusFormats(t *testing.T) {
+	running := daemon.State{
+		SessionType:  pomodoro.Focus,
+		Name:         "report",
+		Tags:         []string{"work", "q4"},
+		Remaining:    10 * time.Minute,
+		Elapsed:      15 * time.Minute,
+		Total:        25 * time.Minute,
+		SessionsDone: 2,
+	}
+	paused := running
+	paused.Name, paused.Tags, paused.Paused = "", nil, true
+	flow := daemon.State{SessionType: pomodoro.Flow, Elapsed: 42*time.Minute + 30*time.Second}
+
+	const template = `{{.State}} {{.Remaining}} [{{join .Tags ","}}] {{.Percent}}%`
+	states := []struct {
+		name  string
+		state *daemon.State // nil when no timer is running
+		want  map[string]string
+	}{
+		{
+			name: "idle",
+			want: map[string]string{
+				"plain":    "idle",
+				"tmux":     "idle",
+				"waybar":   `{"alt":"idle","class":"idle","percentage":0,"text":"idle","tooltip":"No timer running"}`,
+				"i3blocks": `{"full_text":"idle","short_text":"idle"}`,
+				template:   "idle",
+			},
+		},
+		{
+			name:  "running",
+			state: &running,
+			want: map[string]string{
+				"plain":    "Focus Session 10:00 report",
+				"tmux":     "#[fg=red]🍅 10:00#[default]",
+				"waybar":   `{"alt":"focus","class":"focus","percentage":60,"text":"🍅 10:00","tooltip":"Focus Session - report (15:00 of 25:00, 2 done)"}`,
+				"i3blocks": `{"full_text":"🍅 10:00 report","short_text":"10:00"}`,
+				template:   "focus 10:00 [work,q4] 60%",
+			},
+		},
+		{
+			name:  "paused",
+			state: &paused,
+			want: map[string]string{
+				"plain":    "Focus Session 10:00 (paused)",
+				"tmux":     "#[fg=yellow]⏸ 10:00#[default]",
+				"waybar":   `{"alt":"paused","class":"paused","percentage":60,"text":"⏸ 10:00","tooltip":"Focus Session (15:00 of 25:00, 2 done)"}`,
+				"i3blocks": `{"full_text":"⏸ 10:00","short_text":"10:00"}`,
+				template:   "paused 10:00 [] 60%",
+			},
+		},
+		{
+			// Flow sessions count up, so Remaining shows the time so far.
+			name:  "flow",
+			state: &flow,
+			want: map[string]string{
+				"plain":    "Flow Session 42:30",
+				"tmux":     "#[fg=red]🍅 42:30#[default]",
+				"waybar":   `{"alt":"focus","class":"focus","percentage":0,"text":"🍅 42:30","tooltip":"Flow Session (42:30 of 00:00, 0 done)"}`,
+				"i3blocks": `{"full_text":"🍅 42:30","short_text":"42:30"}`,
+				template:   "focus 42:30 [] 0%",
+			},
+		},
+	}
+
+	for _, st := range states {
+		for format, want := range st.want {
+			render, err := statusRenderer(format)
+			if err != nil {
+				t.Fatalf("%s: %v", format, err)
+			}
+			var v *statusView
+			if st.state != nil {
+				view := newStatusView(*st.state)
+				v = &view
+			}
+			got, err := render(v, "idle")
+			if err != nil {
+				t.Errorf("%s, %s: %v", st.name, format, err)
+			} else if got != want {
+				t.Errorf("%s, %s:\n got %s\nwant %s", st.name, format, got, want)
+			}
+		}
+	}
+
+	if _, err := statusRenderer("{{.Remaining"); err == nil {
+		t.Error("unterminated template: got no error")
+	}
+	render, err := statusRenderer("{{.Missing}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	view := newStatusView(running)
+	if _, err := render(&view, "idle"); err == nil {
+		t.Error("unknown field: got no error")
+	}
+}
@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/streak"
+)
+
+var streakCmd = &cobra.Command{
+	Use:   "streak",
+	Short: "Show today's progress towards the daily goal and your streaks",
+	Long: `Show today's progress towards the daily goal and the current and longest
+runs of days on which it was met. Set the goal in the config file:
+
+  [daily_goal]
+  sessions = 8          # or: focus = "3h"
+  weekends_off = true   # weekends neither break nor extend a streak`,
+	Args: cobra.NoArgs,
+	RunE: runStreak,
+}
+
+var streakJSON bool
+
+func init() {
+	streakCmd.Flags().BoolVar(&streakJSON, "json", false, "output as JSON")
+
+	rootCmd.AddCommand(streakCmd)
+}
+
+func runStreak(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	if !cfg.DailyGoal.IsSet() {
+		fmt.Printf("No daily goal set. Add a [daily_goal] section to %s.\n", configPathOrDefault())
+		return nil
+	}
+
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	s, err := streak.Load(context.Background(), store, cfg.DailyGoal, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if streakJSON {
+		result := struct {
+			GoalSessions  int           `json:"goalSessions,omitempty"`
+			GoalFocus     time.Duration `json:"goalFocusTime,omitempty"`
+			Sessions      int           `json:"sessions"`
+			Focus         time.Duration `json:"focusTime"`
+			Percent       float64       `json:"percent"`
+			Met           bool          `json:"met"`
+			CurrentStreak int           `json:"currentStreak"`
+			LongestStreak int           `json:"longestStreak"`
+		}{
+			GoalSessions:  cfg.DailyGoal.Sessions,
+			GoalFocus:     cfg.DailyGoal.Focus,
+			Sessions:      s.Sessions,
+			Focus:         s.Focus,
+			Percent:       s.Percent(),
+			Met:           s.Met(),
+			CurrentStreak: s.Current(),
+			LongestStreak: s.Longest(),
+		}
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
+	fmt.Println(headerStyle.Render("🔥 Streak"))
+	fmt.Println()
+
+	today := fmt.Sprintf("%s (%.0f%%)", s.Progress(), s.Percent())
+	if s.Met() {
+		today += " ✓"
+	}
+	fmt.Printf("Today:          %s\n", today)
+	fmt.Printf("Current streak: %s\n", pluralDays(s.Current()))
+	fmt.Printf("Longest streak: %s\n", pluralDays(s.Longest()))
+	return nil
+}
+
+func pluralDays(n int) string {
+	if n == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", n)
+}
+
+// configPathOrDefault names the config file for hints in messages.
+func configPathOrDefault() string {
+	if p, err := config.Path(); err == nil {
+		return p
+	}
+	return "the config file"
+}
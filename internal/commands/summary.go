@@ -4,13 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
+	"github.com/zjom/pom/internal/config"
 	"github.com/zjom/pom/internal/pomodoro"
 	"github.com/zjom/pom/internal/storage"
 )
@@ -22,40 +27,46 @@ var summaryCmd = &cobra.Command{
 }
 
 var (
-	sumName     string
-	sumFrom     string
-	sumTo       string
-	sumType     string
-	sumJSON     bool
+	sumFilter filterFlags
+	sumBy     string
+	sumGroup  string
+	sumJSON   bool
 )
 
 func init() {
-	summaryCmd.Flags().StringVar(&sumName, "name", "", "filter by session name")
-	summaryCmd.Flags().StringVar(&sumFrom, "from", "", "start date (YYYY-MM-DD)")
-	summaryCmd.Flags().StringVar(&sumTo, "to", "", "end date (YYYY-MM-DD)")
-	summaryCmd.Flags().StringVar(&sumType, "type", "", "filter by type: focus, short-break, long-break")
+	sumFilter.register(summaryCmd.Flags())
+	summaryCmd.Flags().StringVar(&sumBy, "by", "", "break down by: type, profile, project, tag")
+	summaryCmd.Flags().StringVar(&sumGroup, "group-by", "", "time series by: day, week, month, weekday, hour")
+	summaryCmd.MarkFlagsMutuallyExclusive("by", "group-by")
 	summaryCmd.Flags().BoolVar(&sumJSON, "json", false, "output as JSON")
 
 	rootCmd.AddCommand(summaryCmd)
 }
 
 func runSummary(cmd *cobra.Command, args []string) error {
-	dbPath, err := storage.DefaultDBPath()
+	cfg, err := config.Load("")
 	if err != nil {
-		return fmt.Errorf("determine database path: %w", err)
+		return err
 	}
 
-	store, err := storage.NewSQLiteStore(dbPath)
+	store, err := openStore(cfg)
 	if err != nil {
-		return fmt.Errorf("open database: %w", err)
+		return err
 	}
 	defer store.Close()
 
-	f, err := buildFilter(sumName, sumFrom, sumTo, sumType, 0)
+	f, err := buildFilter(sumFilter, 0)
 	if err != nil {
 		return err
 	}
 
+	if sumBy != "" {
+		return runBreakdown(store, f, storage.GroupKey(sumBy), cfg)
+	}
+	if sumGroup != "" {
+		return runTimeSeries(store, f, storage.Period(sumGroup), cfg)
+	}
+
 	stats, err := store.GetStatistics(context.Background(), f)
 	if err != nil {
 		return fmt.Errorf("query statistics: %w", err)
@@ -75,23 +86,37 @@ func runSummary(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205"))
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
 	fmt.Println(headerStyle.Render("📊 Session Summary"))
 	fmt.Println()
 
 	rows := [][]string{
 		{"Total Sessions", fmt.Sprintf("%d", stats.TotalSessions)},
-		{"Total Time", formatDuration(stats.TotalTime)},
-		{"Average Duration", formatDuration(stats.AverageDuration)},
+		{"Total Time", pomodoro.FormatDuration(stats.TotalTime)},
+		{"Average Duration", pomodoro.FormatDuration(stats.AverageDuration)},
+		{"Focus Time (net)", pomodoro.FormatDuration(stats.FocusTime)},
+		{"Focus Time (wall clock)", pomodoro.FormatDuration(stats.FocusWallTime)},
+		{"Planned Focus Time", pomodoro.FormatDuration(stats.PlannedFocusTime)},
+		{"Paused Time", pomodoro.FormatDuration(stats.PausedTime)},
+		{"Idle Time Between Intervals", pomodoro.FormatDuration(stats.IdleTime)},
+		{"Pauses", fmt.Sprintf("%d", stats.Pauses)},
+		{"Internal Interruptions", fmt.Sprintf("%d", stats.InternalInterruptions)},
+		{"External Interruptions", fmt.Sprintf("%d", stats.ExternalInterruptions)},
+		{"Interruptions / Focus Hour", fmt.Sprintf("%.1f", stats.InterruptionsPerFocusHour)},
+		{"Flow Time", pomodoro.FormatDuration(stats.FlowTime)},
+		{"Focus Completion Rate", fmt.Sprintf("%.0f%%", stats.CompletionRate*100)},
 	}
 
 	for typ, count := range stats.ByType {
 		rows = append(rows, []string{typ, fmt.Sprintf("%d", count)})
 	}
+	for status, count := range stats.ByStatus {
+		rows = append(rows, []string{titleCase(status), fmt.Sprintf("%d", count)})
+	}
 
 	t := table.New().
 		Border(lipgloss.NormalBorder()).
-		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color("238"))).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Theme.Border))).
 		Headers("Metric", "Value").
 		Rows(rows...)
 
@@ -100,25 +125,195 @@ func runSummary(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
-func formatDuration(d time.Duration) string {
-	h := int(d.Hours())
-	m := int(d.Minutes()) % 60
-	s := int(d.Seconds()) % 60
-	if h > 0 {
-		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+func runBreakdown(store storage.Store, f storage.QueryFilter, by storage.GroupKey, cfg config.Config) error {
+	ctx := context.Background()
+	groups, err := store.GetBreakdown(ctx, f, by)
+	if err != nil {
+		return fmt.Errorf("query statistics: %w", err)
 	}
-	if m > 0 {
-		return fmt.Sprintf("%dm %ds", m, s)
+	if by == storage.GroupByProject {
+		if err := fillProjectGoals(ctx, store, f, groups); err != nil {
+			return err
+		}
 	}
-	return fmt.Sprintf("%ds", s)
+
+	if sumJSON {
+		data, err := json.MarshalIndent(groups, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if len(groups) == 0 {
+		fmt.Println("No sessions found matching the given filters.")
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
+	fmt.Println(headerStyle.Render(fmt.Sprintf("📊 Session Summary by %s", by)))
+	fmt.Println()
+
+	var rows [][]string
+	for _, g := range groups {
+		key := g.Key
+		if key == "" {
+			key = "(none)"
+		}
+		focus := pomodoro.FormatDuration(g.FocusTime)
+		if g.Goal > 0 {
+			focus = formatGoal(g.FocusTime, g.Goal)
+		}
+		rows = append(rows, []string{
+			key,
+			fmt.Sprintf("%d", g.TotalSessions),
+			fmt.Sprintf("%d", g.FocusSessions),
+			focus,
+			pomodoro.FormatDuration(g.TotalTime),
+		})
+	}
+
+	focusHeader := "Focus Time"
+	if by == storage.GroupByProject {
+		focusHeader = "Focus Time / Goal"
+	}
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Theme.Border))).
+		Headers(titleCase(string(by)), "Sessions", "Focus Sessions", focusHeader, "Total Time").
+		Rows(rows...)
+
+	fmt.Println(t)
+
+	return nil
 }
 
-func buildFilter(name, from, to, typ string, limit int) (storage.QueryFilter, error) {
+func runTimeSeries(store storage.Store, f storage.QueryFilter, period storage.Period, cfg config.Config) error {
+	buckets, err := store.GetTimeSeries(context.Background(), f, period, time.Local)
+	if err != nil {
+		return fmt.Errorf("query statistics: %w", err)
+	}
+
+	if sumJSON {
+		data, err := json.MarshalIndent(buckets, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if !slices.ContainsFunc(buckets, func(b storage.Bucket) bool { return b.TotalSessions > 0 }) {
+		fmt.Println("No sessions found matching the given filters.")
+		return nil
+	}
+
+	headerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(cfg.Theme.Title))
+	fmt.Println(headerStyle.Render(fmt.Sprintf("📊 Session Summary by %s", period)))
+	fmt.Println()
+
+	var rows [][]string
+	for _, b := range buckets {
+		rows = append(rows, []string{
+			b.Key,
+			fmt.Sprintf("%d", b.TotalSessions),
+			fmt.Sprintf("%d", b.FocusSessions),
+			pomodoro.FormatDuration(b.FocusTime),
+			pomodoro.FormatDuration(b.TotalTime),
+			fmt.Sprintf("%d", b.Interruptions),
+		})
+	}
+
+	t := table.New().
+		Border(lipgloss.NormalBorder()).
+		BorderStyle(lipgloss.NewStyle().Foreground(lipgloss.Color(cfg.Theme.Border))).
+		Headers(titleCase(string(period)), "Sessions", "Focus Sessions", "Focus Time", "Total Time", "Interruptions").
+		Rows(rows...)
+
+	fmt.Println(t)
+
+	return nil
+}
+
+// fillProjectGoals sets each project group's goal to its weekly goal times
+// the number of weeks the filter covers. Without --from the range starts when
+// the project was created; without --to it ends now.
+func fillProjectGoals(ctx context.Context, store storage.Store, f storage.QueryFilter, groups []storage.GroupStats) error {
+	projects, err := store.ListProjects(ctx, true)
+	if err != nil {
+		return fmt.Errorf("list projects: %w", err)
+	}
+
+	for i, g := range groups {
+		idx := slices.IndexFunc(projects, func(p storage.Project) bool { return p.Name == g.Key })
+		if idx < 0 || projects[idx].WeeklyGoal == 0 {
+			continue
+		}
+		p := projects[idx]
+
+		from, to := p.CreatedAt, time.Now()
+		if f.From != nil && f.From.After(from) {
+			from = *f.From
+		}
+		if f.To != nil {
+			to = *f.To
+		}
+		weeks := math.Max(1, math.Ceil(to.Sub(from).Hours()/(7*24)))
+		groups[i].Goal = time.Duration(weeks * float64(p.WeeklyGoal))
+	}
+	return nil
+}
+
+// filterFlags holds the session filter flags shared by history and summary.
+type filterFlags struct {
+	name     string
+	profile  string
+	project  string
+	tags     []string
+	tagMatch string
+	from     string
+	to       string
+	typ      string
+}
+
+func (ff *filterFlags) register(fs *pflag.FlagSet) {
+	fs.StringVar(&ff.name, "name", "", "filter by session name")
+	fs.StringVar(&ff.profile, "profile", "", "filter by timing profile")
+	fs.StringVar(&ff.project, "project", "", "filter by project")
+	fs.StringArrayVar(&ff.tags, "tag", nil, "filter by tag (repeatable)")
+	fs.StringVar(&ff.tagMatch, "tag-match", "any", "with several --tag flags, match sessions with any or all of them")
+	fs.StringVar(&ff.from, "from", "", "start date (YYYY-MM-DD)")
+	fs.StringVar(&ff.to, "to", "", "end date (YYYY-MM-DD)")
+	fs.StringVar(&ff.typ, "type", "", "filter by type: focus, flow, short-break, long-break")
+}
+
+// titleCase upper-cases the first letter of s.
+func titleCase(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func buildFilter(ff filterFlags, limit int) (storage.QueryFilter, error) {
 	f := storage.QueryFilter{
-		Name:  name,
-		Limit: limit,
+		Name:    ff.name,
+		Profile: ff.profile,
+		Project: ff.project,
+		Tags:    normalizeTags(ff.tags),
+		Limit:   limit,
 	}
 
+	switch ff.tagMatch {
+	case "any":
+	case "all":
+		f.MatchAllTags = true
+	default:
+		return f, fmt.Errorf("invalid --tag-match %q: want any or all", ff.tagMatch)
+	}
+	from, to, typ := ff.from, ff.to, ff.typ
+
 	if from != "" {
 		t, err := time.Parse("2006-01-02", from)
 		if err != nil {
@@ -138,15 +333,8 @@ func buildFilter(name, from, to, typ string, limit int) (storage.QueryFilter, er
 	}
 
 	if typ != "" {
-		var st pomodoro.SessionType
-		switch typ {
-		case "focus":
-			st = pomodoro.Focus
-		case "short-break":
-			st = pomodoro.ShortBreak
-		case "long-break":
-			st = pomodoro.LongBreak
-		default:
+		st, ok := parseSessionType(typ)
+		if !ok {
 			fmt.Fprintf(os.Stderr, "Warning: unknown type %q, ignoring filter\n", typ)
 			return f, nil
 		}
@@ -155,3 +343,18 @@ func buildFilter(name, from, to, typ string, limit int) (storage.QueryFilter, er
 
 	return f, nil
 }
+
+// parseSessionType maps a --type flag value to a session type.
+func parseSessionType(s string) (pomodoro.SessionType, bool) {
+	switch s {
+	case "focus":
+		return pomodoro.Focus, true
+	case "flow":
+		return pomodoro.Flow, true
+	case "short-break":
+		return pomodoro.ShortBreak, true
+	case "long-break":
+		return pomodoro.LongBreak, true
+	}
+	return "", false
+}
@@ -0,0 +1,52 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/storage"
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo",
+	Short: "Revert the most recent `pom edit` or `pom delete`",
+	Args:  cobra.NoArgs,
+	RunE:  runUndo,
+}
+
+func init() {
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load("")
+	if err != nil {
+		return err
+	}
+	store, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	e, err := store.Undo(context.Background())
+	if errors.Is(err, storage.ErrNothingToUndo) {
+		fmt.Println("Nothing to undo.")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("undo: %w", err)
+	}
+
+	switch e.Action {
+	case storage.ActionDelete:
+		fmt.Printf("Restored deleted session %d.\n", e.SessionID)
+	default:
+		fmt.Printf("Reverted %s of session %d.\n", e.Action, e.SessionID)
+	}
+	return nil
+}
@@ -1,13 +1,97 @@
 package config
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type Config struct {
-	SessionName     string
-	SessionDuration time.Duration
-	ShortBreak      time.Duration
-	LongBreak       time.Duration
-	SessionsToLong  int
+	SessionName     string        `toml:"name"`
+	Tags            []string      `toml:"tags"`
+	SessionDuration time.Duration `toml:"focus"`
+	ShortBreak      time.Duration `toml:"short_break"`
+	LongBreak       time.Duration `toml:"long_break"`
+	SessionsToLong  int           `toml:"sessions_to_long"`
+	Profile         string        `toml:"profile"`
+	Project         string        `toml:"project"`
+	AutoStartBreaks bool          `toml:"auto_start_breaks"`
+	AutoStartFocus  bool          `toml:"auto_start_focus"`
+	NotePrompt      bool          `toml:"note_prompt"`
+	FlowMode        bool          `toml:"-"`
+	Sequence        string        `toml:"sequence"`
+	Flow            Flow          `toml:"flow"`
+	DailyGoal       DailyGoal     `toml:"daily_goal"`
+	Suspend         Suspend       `toml:"suspend"`
+	DBPath          string        `toml:"db_path"`
+	Notifications   Notifications `toml:"notifications"`
+	Theme           Theme         `toml:"theme"`
+
+	Profiles  map[string]Profile  `toml:"profiles"`
+	Sequences map[string]Sequence `toml:"sequences"`
+}
+
+// Flow configures flowtime mode, where focus counts up with no target and
+// the following break lasts BreakRatio of the focus time, clamped to
+// [MinBreak, MaxBreak].
+type Flow struct {
+	BreakRatio float64       `toml:"break_ratio"`
+	MinBreak   time.Duration `toml:"min_break"`
+	MaxBreak   time.Duration `toml:"max_break"`
+}
+
+// DailyGoal is the focus target for each day, counted either in completed
+// focus sessions or in focus time; at most one may be set. With WeekendsOff,
+// Saturdays and Sundays are left out of streaks: missing the goal on them
+// doesn't break one and meeting it doesn't extend one.
+type DailyGoal struct {
+	Sessions    int           `toml:"sessions"`
+	Focus       time.Duration `toml:"focus"`
+	WeekendsOff bool          `toml:"weekends_off"`
+}
+
+// IsSet reports whether a goal has been configured.
+func (g DailyGoal) IsSet() bool {
+	return g.Sessions > 0 || g.Focus > 0
+}
+
+// Suspend policies, see Suspend.
+const (
+	SuspendCount = "count"
+	SuspendPause = "pause"
+	SuspendAbort = "abort"
+)
+
+// Suspend decides what happens to a running interval when the timer stops
+// ticking for longer than Threshold, because the computer slept or its clock
+// jumped ahead. With Policy "count" the lost time counts towards the
+// interval, "pause" records it as a pause, and "abort" ends the interval as
+// aborted when the gap began and waits to run it again.
+//
+// Sleep is only detected on Linux. On macOS and Windows the clock pom uses
+// to notice it keeps running while the computer sleeps, so the time asleep
+// always counts towards the interval, whatever the policy; only the clock
+// jumping ahead is caught there.
+type Suspend struct {
+	Policy    string        `toml:"policy"`
+	Threshold time.Duration `toml:"threshold"`
+}
+
+// Notifications holds the desktop notification text sent when an interval ends.
+type Notifications struct {
+	Title      string `toml:"title"`
+	ShortBreak string `toml:"short_break"`
+	LongBreak  string `toml:"long_break"`
+	Focus      string `toml:"focus"`
+	Finished   string `toml:"finished"`
+}
+
+// Theme holds the lipgloss colours used by the TUI and command output.
+type Theme struct {
+	Title  string `toml:"title"`
+	Status string `toml:"status"`
+	Timer  string `toml:"timer"`
+	Help   string `toml:"help"`
+	Border string `toml:"border"`
 }
 
 func Default() Config {
@@ -16,5 +100,110 @@ func Default() Config {
 		ShortBreak:      5 * time.Minute,
 		LongBreak:       15 * time.Minute,
 		SessionsToLong:  4,
+		AutoStartBreaks: true,
+		AutoStartFocus:  true,
+		Flow: Flow{
+			BreakRatio: 0.2,
+			MinBreak:   3 * time.Minute,
+			MaxBreak:   30 * time.Minute,
+		},
+		Suspend: Suspend{
+			Policy:    SuspendPause,
+			Threshold: time.Minute,
+		},
+		Notifications: Notifications{
+			Title:      "Pomodoro",
+			ShortBreak: "Focus session complete! Take a quick breather.",
+			LongBreak:  "Focus session complete! Time for a long break.",
+			Focus:      "Break is over. Time to get back to focus!",
+			Finished:   "Sequence complete. Nice work!",
+		},
+		Theme: Theme{
+			Title:  "205",
+			Status: "69",
+			Timer:  "43",
+			Help:   "241",
+			Border: "238",
+		},
+	}
+}
+
+// Validate reports the first invalid field, naming it by its config file key.
+func (c Config) Validate() error {
+	durations := []struct {
+		key string
+		d   time.Duration
+	}{
+		{"focus", c.SessionDuration},
+		{"short_break", c.ShortBreak},
+		{"long_break", c.LongBreak},
+	}
+	for _, d := range durations {
+		if d.d < time.Second {
+			return fmt.Errorf("%s: must be at least 1s, got %s", d.key, d.d)
+		}
+	}
+	if c.SessionsToLong < 1 {
+		return fmt.Errorf("sessions_to_long: must be at least 1, got %d", c.SessionsToLong)
+	}
+	if c.Flow.BreakRatio <= 0 || c.Flow.BreakRatio > 1 {
+		return fmt.Errorf("flow.break_ratio: must be in (0, 1], got %g", c.Flow.BreakRatio)
+	}
+	if c.Flow.MinBreak < 0 {
+		return fmt.Errorf("flow.min_break: must not be negative, got %s", c.Flow.MinBreak)
+	}
+	if c.Flow.MaxBreak < c.Flow.MinBreak {
+		return fmt.Errorf("flow.max_break: must be at least flow.min_break (%s), got %s", c.Flow.MinBreak, c.Flow.MaxBreak)
+	}
+	if c.DailyGoal.Sessions < 0 {
+		return fmt.Errorf("daily_goal.sessions: must not be negative, got %d", c.DailyGoal.Sessions)
+	}
+	if c.DailyGoal.Focus < 0 {
+		return fmt.Errorf("daily_goal.focus: must not be negative, got %s", c.DailyGoal.Focus)
+	}
+	if c.DailyGoal.Sessions > 0 && c.DailyGoal.Focus > 0 {
+		return fmt.Errorf("daily_goal: set sessions or focus, not both")
+	}
+	switch c.Suspend.Policy {
+	case SuspendCount, SuspendPause, SuspendAbort:
+	default:
+		return fmt.Errorf("suspend.policy: must be count, pause or abort, got %q", c.Suspend.Policy)
+	}
+	if c.Suspend.Threshold < 5*time.Second {
+		return fmt.Errorf("suspend.threshold: must be at least 5s, got %s", c.Suspend.Threshold)
+	}
+
+	for name, p := range c.Profiles {
+		durations := []struct {
+			key string
+			d   time.Duration
+		}{
+			{"focus", p.SessionDuration},
+			{"short_break", p.ShortBreak},
+			{"long_break", p.LongBreak},
+		}
+		for _, d := range durations {
+			if d.d != 0 && d.d < time.Second {
+				return fmt.Errorf("profiles.%s.%s: must be at least 1s, got %s", name, d.key, d.d)
+			}
+		}
+		if p.SessionsToLong < 0 {
+			return fmt.Errorf("profiles.%s.sessions_to_long: must not be negative, got %d", name, p.SessionsToLong)
+		}
+	}
+
+	for name, seq := range c.Sequences {
+		if err := seq.validate("sequences." + name); err != nil {
+			return err
+		}
+	}
+	if c.Sequence != "" {
+		if _, ok := c.Sequences[c.Sequence]; !ok {
+			return fmt.Errorf("sequence: no [sequences.%s] defined", c.Sequence)
+		}
+		if c.FlowMode {
+			return fmt.Errorf("sequence: cannot be combined with flow mode")
+		}
 	}
+	return nil
 }
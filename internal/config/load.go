@@ -0,0 +1,138 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Path returns the config file location: $POM_CONFIG if set, otherwise
+// $XDG_CONFIG_HOME/pom/config.toml (falling back to ~/.config).
+func Path() (string, error) {
+	if p := os.Getenv("POM_CONFIG"); p != "" {
+		return p, nil
+	}
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "pom", "config.toml"), nil
+}
+
+// Load builds a Config from the defaults, the config file, the selected
+// profile and POM_* environment variables, in increasing order of precedence.
+// profile overrides the profile named by POM_PROFILE or the config file; pass
+// "" to keep it. A missing config file is not an error. Command-line flags are
+// applied on top by the caller.
+func Load(profile string) (Config, error) {
+	cfg := Default()
+
+	path, err := Path()
+	if err != nil {
+		return cfg, fmt.Errorf("determine config path: %w", err)
+	}
+	if err := loadFile(path, &cfg); err != nil {
+		return cfg, err
+	}
+
+	if profile == "" {
+		profile = os.Getenv("POM_PROFILE")
+	}
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return cfg, err
+		}
+	}
+
+	if err := loadEnv(&cfg); err != nil {
+		return cfg, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return cfg, fmt.Errorf("invalid config: %w", err)
+	}
+	return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+	md, err := toml.DecodeFile(path, cfg)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		var perr toml.ParseError
+		if errors.As(err, &perr) {
+			return fmt.Errorf("%s: %s", path, perr.ErrorWithPosition())
+		}
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return fmt.Errorf("%s: unknown key %q", path, undecoded[0].String())
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	cfg.DBPath = expandHome(cfg.DBPath)
+	return nil
+}
+
+func loadEnv(cfg *Config) error {
+	durations := []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"POM_FOCUS", &cfg.SessionDuration},
+		{"POM_SHORT_BREAK", &cfg.ShortBreak},
+		{"POM_LONG_BREAK", &cfg.LongBreak},
+	}
+	for _, d := range durations {
+		v, ok := os.LookupEnv(d.env)
+		if !ok || v == "" {
+			continue
+		}
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", d.env, err)
+		}
+		*d.dst = dur
+	}
+
+	if v := os.Getenv("POM_SESSIONS_TO_LONG"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("POM_SESSIONS_TO_LONG: %w", err)
+		}
+		cfg.SessionsToLong = n
+	}
+	if v := os.Getenv("POM_NAME"); v != "" {
+		cfg.SessionName = v
+	}
+	if v := os.Getenv("POM_DB_PATH"); v != "" {
+		cfg.DBPath = expandHome(v)
+	}
+	return nil
+}
+
+func expandHome(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
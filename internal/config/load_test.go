@@ -0,0 +1,198 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// envVars are the variables Load reads; every test starts with them unset.
+var envVars = []string{
+	"POM_PROFILE", "POM_FOCUS", "POM_SHORT_BREAK", "POM_LONG_BREAK", "POM_SESSIONS_TO_LONG", "POM_NAME", "POM_DB_PATH",
+}
+
+func TestLoad(t *testing.T) {
+	const m = time.Minute
+
+	// A file that sets some durations and selects a profile that
+	// overrides one of them.
+	const withProfile = `
+focus = "30m"
+short_break = "6m"
+name = "from file"
+profile = "work"
+
+[profiles.work]
+short_break = "7m"
+`
+
+	tests := []struct {
+		name    string
+		file    string // "" for no config file
+		env     map[string]string
+		profile string
+		// want
+		focus, short, long time.Duration
+		toLong             int
+		session, selected  string
+		err                string // substring of the error, if one is expected
+	}{
+		{
+			name:  "defaults",
+			focus: 25 * m, short: 5 * m, long: 15 * m, toLong: 4,
+		},
+		{
+			name:  "file over defaults",
+			file:  "focus = \"30m\"\nshort_break = \"6m\"\nname = \"from file\"\n",
+			focus: 30 * m, short: 6 * m, long: 15 * m, toLong: 4, session: "from file",
+		},
+		{
+			name:  "profile over file",
+			file:  withProfile,
+			focus: 30 * m, short: 7 * m, long: 15 * m, toLong: 4, session: "from file", selected: "work",
+		},
+		{
+			name:  "environment over profile",
+			file:  withProfile,
+			env:   map[string]string{"POM_SHORT_BREAK": "8m", "POM_FOCUS": "40m", "POM_NAME": "from env", "POM_SESSIONS_TO_LONG": "2"},
+			focus: 40 * m, short: 8 * m, long: 15 * m, toLong: 2, session: "from env", selected: "work",
+		},
+		{
+			name:  "POM_PROFILE over the file's profile",
+			file:  withProfile,
+			env:   map[string]string{"POM_PROFILE": "deep"},
+			focus: 50 * m, short: 10 * m, long: 30 * m, toLong: 3, session: "from file", selected: "deep",
+		},
+		{
+			name:    "argument over POM_PROFILE, environment over both",
+			file:    withProfile,
+			env:     map[string]string{"POM_PROFILE": "deep", "POM_LONG_BREAK": "20m"},
+			profile: "sprint",
+			focus:   15 * m, short: 3 * m, long: 20 * m, toLong: 4, session: "from file", selected: "sprint",
+		},
+		{
+			name: "invalid short_break in the file",
+			file: "short_break = \"0s\"\n",
+			err:  "config.toml: short_break: must be at least 1s",
+		},
+		{
+			name: "invalid short_break in the environment",
+			env:  map[string]string{"POM_SHORT_BREAK": "500ms"},
+			err:  "invalid config: short_break: must be at least 1s, got 500ms",
+		},
+		{
+			name: "invalid short_break in a profile",
+			file: "[profiles.quick]\nshort_break = \"10ms\"\n",
+			err:  "profiles.quick.short_break: must be at least 1s",
+		},
+		{
+			name: "unparsable environment variable",
+			env:  map[string]string{"POM_SHORT_BREAK": "five"},
+			err:  "POM_SHORT_BREAK",
+		},
+		{
+			name: "unknown key",
+			file: "shortbreak = \"5m\"\n",
+			err:  `unknown key "shortbreak"`,
+		},
+		{
+			name:    "unknown profile",
+			profile: "marathon",
+			err:     `unknown profile "marathon"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if tt.file != "" {
+				if err := os.WriteFile(path, []byte(tt.file), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			t.Setenv("POM_CONFIG", path)
+			for _, v := range envVars {
+				t.Setenv(v, tt.env[v])
+			}
+
+			cfg, err := Load(tt.profile)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("got error %v, want one containing %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.SessionDuration != tt.focus || cfg.ShortBreak != tt.short || cfg.LongBreak != tt.long || cfg.SessionsToLong != tt.toLong {
+				t.Errorf("got %s/%s/%s every %d, want %s/%s/%s every %d",
+					cfg.SessionDuration, cfg.ShortBreak, cfg.LongBreak, cfg.SessionsToLong, tt.focus, tt.short, tt.long, tt.toLong)
+			}
+			if cfg.SessionName != tt.session || cfg.Profile != tt.selected {
+				t.Errorf("got name %q, profile %q; want %q, %q", cfg.SessionName, cfg.Profile, tt.session, tt.selected)
+			}
+		})
+	}
+}
+
+func TestLoadDBPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte("db_path = \"~/pom/history.db\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("POM_CONFIG", path)
+	for _, v := range envVars {
+		t.Setenv(v, "")
+	}
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "pom", "history.db"); cfg.DBPath != want {
+		t.Errorf("file: got %s, want %s", cfg.DBPath, want)
+	}
+
+	t.Setenv("POM_DB_PATH", "~/elsewhere.db")
+	if cfg, err = Load(""); err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "elsewhere.db"); cfg.DBPath != want {
+		t.Errorf("environment: got %s, want %s", cfg.DBPath, want)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c *Config)
+		err    string // "" if valid
+	}{
+		{name: "defaults", change: func(c *Config) {}},
+		{name: "short break", change: func(c *Config) { c.ShortBreak = 0 }, err: "short_break:"},
+		{name: "negative focus", change: func(c *Config) { c.SessionDuration = -time.Minute }, err: "focus:"},
+		{name: "long break", change: func(c *Config) { c.LongBreak = time.Millisecond }, err: "long_break:"},
+		{name: "sessions to long", change: func(c *Config) { c.SessionsToLong = 0 }, err: "sessions_to_long:"},
+		{name: "break ratio", change: func(c *Config) { c.Flow.BreakRatio = 1.5 }, err: "flow.break_ratio:"},
+		{name: "both goals", change: func(c *Config) { c.DailyGoal = DailyGoal{Sessions: 4, Focus: time.Hour} }, err: "daily_goal:"},
+		{name: "suspend policy", change: func(c *Config) { c.Suspend.Policy = "ignore" }, err: "suspend.policy:"},
+		{name: "missing sequence", change: func(c *Config) { c.Sequence = "day" }, err: "sequence:"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Default()
+			tt.change(&c)
+			err := c.Validate()
+			switch {
+			case tt.err == "" && err != nil:
+				t.Errorf("got %v, want no error", err)
+			case tt.err != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.err)):
+				t.Errorf("got %v, want an error starting %q", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Profile is a named timing rhythm. Zero fields leave the corresponding
+// Config value untouched.
+type Profile struct {
+	SessionDuration time.Duration `toml:"focus"`
+	ShortBreak      time.Duration `toml:"short_break"`
+	LongBreak       time.Duration `toml:"long_break"`
+	SessionsToLong  int           `toml:"sessions_to_long"`
+}
+
+// BuiltinProfiles are available without any configuration. Profiles of the
+// same name in the config file replace them.
+var BuiltinProfiles = map[string]Profile{
+	"classic": {25 * time.Minute, 5 * time.Minute, 15 * time.Minute, 4},
+	"deep":    {50 * time.Minute, 10 * time.Minute, 30 * time.Minute, 3},
+	"sprint":  {15 * time.Minute, 3 * time.Minute, 10 * time.Minute, 4},
+}
+
+// LookupProfile returns the named profile from the config file or the
+// built-in set.
+func (c Config) LookupProfile(name string) (Profile, bool) {
+	if p, ok := c.Profiles[name]; ok {
+		return p, true
+	}
+	p, ok := BuiltinProfiles[name]
+	return p, ok
+}
+
+// ProfileNames returns every profile name known to c, sorted.
+func (c Config) ProfileNames() []string {
+	names := slices.Collect(maps.Keys(BuiltinProfiles))
+	for name := range c.Profiles {
+		if _, ok := BuiltinProfiles[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ApplyProfile overwrites the timing fields of c with those set in the named
+// profile and records the profile name.
+func (c *Config) ApplyProfile(name string) error {
+	p, ok := c.LookupProfile(name)
+	if !ok {
+		return fmt.Errorf("unknown profile %q (available: %s)", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if p.SessionDuration != 0 {
+		c.SessionDuration = p.SessionDuration
+	}
+	if p.ShortBreak != 0 {
+		c.ShortBreak = p.ShortBreak
+	}
+	if p.LongBreak != 0 {
+		c.LongBreak = p.LongBreak
+	}
+	if p.SessionsToLong != 0 {
+		c.SessionsToLong = p.SessionsToLong
+	}
+	c.Profile = name
+	return nil
+}
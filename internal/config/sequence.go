@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Sequence is an ordered list of intervals run in place of the classic
+// focus/break cycle, e.g. for a structured workday.
+type Sequence struct {
+	Steps []Step `toml:"steps"`
+	// Repeat is how many times the steps run; 0 repeats until stopped.
+	Repeat int `toml:"repeat"`
+	// Until is an optional local time of day ("17:30") after which no new
+	// step is started.
+	Until string `toml:"until"`
+}
+
+// Step is one interval of a Sequence, written in the config file as
+// "<name> <duration> [work|break]", e.g. "focus 50m" or "lunch 45m break".
+// The names focus, break and long-break map to the built-in session types,
+// whose kind is fixed; any other name becomes a session type of its own and
+// counts as work unless marked as a break.
+type Step struct {
+	Name     string
+	Duration time.Duration
+	Break    bool // a rest interval rather than work, see auto_start_breaks
+}
+
+// builtinSteps are the step names with a built-in session type, and whether
+// each is a break.
+var builtinSteps = map[string]bool{
+	"focus":       false,
+	"break":       true,
+	"short-break": true,
+	"long-break":  true,
+}
+
+func (s *Step) UnmarshalText(text []byte) error {
+	fields := strings.Fields(string(text))
+	if len(fields) != 2 && len(fields) != 3 {
+		return fmt.Errorf("step %q: want \"<name> <duration> [work|break]\"", text)
+	}
+	d, err := time.ParseDuration(fields[1])
+	if err != nil {
+		return fmt.Errorf("step %q: %w", text, err)
+	}
+	isBreak, builtin := builtinSteps[fields[0]]
+	if len(fields) == 3 {
+		var explicit bool
+		switch fields[2] {
+		case "work":
+		case "break":
+			explicit = true
+		default:
+			return fmt.Errorf("step %q: kind must be work or break, got %q", text, fields[2])
+		}
+		if builtin && explicit != isBreak {
+			return fmt.Errorf("step %q: %s is always %s", text, fields[0], stepKind(isBreak))
+		}
+		isBreak = explicit
+	}
+	s.Name, s.Duration, s.Break = fields[0], d, isBreak
+	return nil
+}
+
+func (s Step) MarshalText() ([]byte, error) {
+	text := s.Name + " " + s.Duration.String()
+	if _, builtin := builtinSteps[s.Name]; !builtin && s.Break {
+		text += " " + stepKind(s.Break)
+	}
+	return []byte(text), nil
+}
+
+func stepKind(isBreak bool) string {
+	if isBreak {
+		return "break"
+	}
+	return "work"
+}
+
+// UntilTime returns the end time of s on the day of now, and false if s has
+// no end time.
+func (s Sequence) UntilTime(now time.Time) (time.Time, bool) {
+	if s.Until == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse("15:04", s.Until)
+	if err != nil {
+		return time.Time{}, false
+	}
+	y, m, d := now.Date()
+	return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, now.Location()), true
+}
+
+// ActiveSequence returns the sequence selected by c.Sequence, if any.
+func (c Config) ActiveSequence() (Sequence, bool) {
+	if c.Sequence == "" {
+		return Sequence{}, false
+	}
+	s, ok := c.Sequences[c.Sequence]
+	return s, ok
+}
+
+func (s Sequence) validate(key string) error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("%s.steps: must list at least one step", key)
+	}
+	for i, step := range s.Steps {
+		if step.Duration < time.Second {
+			return fmt.Errorf("%s.steps[%d]: duration must be at least 1s, got %s", key, i, step.Duration)
+		}
+	}
+	if s.Repeat < 0 {
+		return fmt.Errorf("%s.repeat: must not be negative, got %d", key, s.Repeat)
+	}
+	if s.Until != "" {
+		if _, err := time.Parse("15:04", s.Until); err != nil {
+			return fmt.Errorf("%s.until: want HH:MM, got %q", key, s.Until)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// next returns the first Update from sub for which ok is true, failing the
+// test if none arrives within a few ticks.
+func next(t *testing.T, sub *Subscription, ok func(State) bool) State {
+	t.Helper()
+	for range 5 {
+		u, err := sub.Next()
+		if err != nil {
+			t.Fatalf("next update: %v", err)
+		}
+		if ok(u.State) {
+			return u.State
+		}
+	}
+	t.Fatal("no matching update")
+	return State{}
+}
+
+func TestAttachDetach(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	if _, err := Call(CmdStatus); !errors.Is(err, ErrNotRunning) {
+		t.Fatalf("status before starting: got %v, want ErrNotRunning", err)
+	}
+
+	ln, err := Listen()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	timer := NewTimer(config.Default(), nil)
+	go Serve(ln, timer)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ran := make(chan struct{})
+	go func() {
+		timer.Run(ctx)
+		close(ran)
+	}()
+
+	if _, err := Listen(); err == nil {
+		t.Error("second Listen: got no error while a timer is running")
+	}
+
+	sub, err := Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := next(t, sub, func(State) bool { return true })
+	if first.SessionType != pomodoro.Focus || first.Paused || first.Remaining <= 0 || first.Total != 25*time.Minute {
+		t.Errorf("first update: %+v", first)
+	}
+
+	st, err := Call(CmdPause)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !st.Paused {
+		t.Errorf("pause: got %+v", st)
+	}
+	next(t, sub, func(s State) bool { return s.Paused })
+	if err := sub.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Detaching leaves the timer running, still paused, with its countdown
+	// frozen across ticks.
+	time.Sleep(1500 * time.Millisecond)
+	select {
+	case <-ran:
+		t.Fatal("timer stopped when the client detached")
+	default:
+	}
+	after, err := Call(CmdStatus)
+	if err != nil {
+		t.Fatalf("status after detaching: %v", err)
+	}
+	if !after.Paused || after.SessionType != pomodoro.Focus || after.Remaining != st.Remaining || !after.TimerStartedAt.Equal(st.TimerStartedAt) {
+		t.Errorf("after detaching:\n got %+v\nwant the paused state %+v", after, st)
+	}
+
+	// A new client picks up where the last left off.
+	sub, err = Attach()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := next(t, sub, func(State) bool { return true }); !again.Paused || again.Remaining != st.Remaining {
+		t.Errorf("reattached: got %+v", again)
+	}
+	if st, err := Call(CmdResume); err != nil || st.Paused {
+		t.Errorf("resume: got %+v, %v", st, err)
+	}
+	next(t, sub, func(s State) bool { return !s.Paused })
+
+	if _, err := Call(CmdStop); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ran:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timer still running after stop")
+	}
+	// The attached client sees the timer go.
+	for {
+		if _, err := sub.Next(); err != nil {
+			break
+		}
+	}
+	sub.Close()
+}
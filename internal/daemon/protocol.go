@@ -0,0 +1,200 @@
+// Package daemon runs the timer in a background process and lets other
+// processes query and control it over a Unix socket. The TUI, `pom status`
+// and friends are all clients; the TUI attaches to receive updates as they
+// happen, and detaching leaves the timer running.
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// Commands understood by the socket server.
+const (
+	CmdStatus    = "status"
+	CmdPause     = "pause"
+	CmdResume    = "resume"
+	CmdSkip      = "skip"
+	CmdStop      = "stop"
+	CmdComplete  = "complete"  // end a flow session or a due interval, with Text as its note
+	CmdExtend    = "extend"    // lengthen the current interval by Duration
+	CmdRestart   = "restart"   // run the current interval again
+	CmdInterrupt = "interrupt" // log an interruption of Kind, with Text as its note
+	CmdRename    = "rename"    // rename this and later intervals to Text
+	CmdAttach    = "attach"    // keep the connection open and receive Updates
+)
+
+// ErrNotRunning is returned by Call when no timer is listening on the socket.
+var ErrNotRunning = errors.New("no timer running")
+
+// State is a snapshot of a running timer. Remaining is zero for flow sessions,
+// which have no target. At is when the snapshot was taken, by the timer's
+// clock.
+type State struct {
+	SessionType    pomodoro.SessionType    `json:"sessionType"`
+	Name           string                  `json:"name,omitempty"`
+	Profile        string                  `json:"profile,omitempty"`
+	Project        string                  `json:"project,omitempty"`
+	Tags           []string                `json:"tags,omitempty"`
+	Paused         bool                    `json:"paused"`
+	Waiting        bool                    `json:"waiting"` // ready but not started, see auto_start_*
+	Break          bool                    `json:"break"`   // a rest interval rather than work
+	Due            bool                    `json:"due,omitempty"`
+	Remaining      time.Duration           `json:"remaining"`
+	Elapsed        time.Duration           `json:"elapsed"`
+	Total          time.Duration           `json:"total"`
+	Extended       time.Duration           `json:"extended,omitempty"`
+	SessionsDone   int                     `json:"sessionsDone"`
+	Sequence       string                  `json:"sequence,omitempty"`
+	Step           int                     `json:"step,omitempty"`   // 1-based position in the sequence's steps
+	Steps          int                     `json:"steps,omitempty"`  // steps per round
+	Round          int                     `json:"round,omitempty"`  // 1-based
+	Rounds         int                     `json:"rounds,omitempty"` // 0 repeats until stopped
+	Interruptions  []pomodoro.Interruption `json:"interruptions,omitempty"`
+	NotePrompt     bool                    `json:"notePrompt,omitempty"` // attached clients ask for a note before completing focus
+	StartedAt      time.Time               `json:"startedAt"`
+	TimerStartedAt time.Time               `json:"timerStartedAt"`
+	At             time.Time               `json:"at"`
+	Stopped        bool                    `json:"stopped,omitempty"` // the timer has shut down
+}
+
+// NewState describes an engine running with cfg.
+func NewState(cfg config.Config, s pomodoro.State) State {
+	st := State{
+		SessionType:   s.Current,
+		Name:          cfg.SessionName,
+		Profile:       cfg.Profile,
+		Project:       cfg.Project,
+		Tags:          cfg.Tags,
+		Paused:        s.Paused,
+		Waiting:       s.Waiting,
+		Break:         s.IsBreak(cfg),
+		Due:           s.Due,
+		Remaining:     s.Remaining,
+		Elapsed:       s.Elapsed,
+		Total:         s.Total,
+		Extended:      s.Extended,
+		SessionsDone:  s.SessionsDone,
+		Interruptions: s.Interruptions,
+		NotePrompt:    cfg.NotePrompt,
+		StartedAt:     s.StartedAt,
+		Stopped:       s.Done,
+	}
+	if seq, ok := cfg.ActiveSequence(); ok {
+		st.Sequence = cfg.Sequence
+		st.Step = s.Step%len(seq.Steps) + 1
+		st.Steps = len(seq.Steps)
+		st.Round = s.Step/len(seq.Steps) + 1
+		st.Rounds = seq.Repeat
+	}
+	return st
+}
+
+// Request is a command sent to the timer. Only the fields its command
+// names are used.
+type Request struct {
+	Cmd      string                    `json:"cmd"`
+	Duration time.Duration             `json:"duration,omitempty"`
+	Kind     pomodoro.InterruptionKind `json:"kind,omitempty"`
+	Text     string                    `json:"text,omitempty"`
+	At       time.Time                 `json:"at,omitzero"` // when a completed interval ended, if earlier than now
+}
+
+// Update is sent to attached clients once a second and whenever the timer
+// emits an event.
+type Update struct {
+	State State           `json:"state"`
+	Event *pomodoro.Event `json:"event,omitempty"`
+}
+
+type response struct {
+	State *State `json:"state,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// SocketPath returns $XDG_RUNTIME_DIR/pom.sock, falling back to a per-user
+// socket in the system temp directory.
+func SocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "pom.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("pom-%d.sock", os.Getuid()))
+}
+
+// Call sends cmd to the running timer and returns its state afterwards.
+func Call(cmd string) (*State, error) {
+	return Send(Request{Cmd: cmd})
+}
+
+// Send is Call for commands that take arguments.
+func Send(req Request) (*State, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, fmt.Errorf("send %s: %w", req.Cmd, err)
+	}
+	var resp response
+	if err := json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, fmt.Errorf("read reply to %s: %w", req.Cmd, err)
+	}
+	if resp.Error != "" {
+		return nil, errors.New(resp.Error)
+	}
+	return resp.State, nil
+}
+
+// Subscription delivers Updates from the running timer to an attached
+// client.
+type Subscription struct {
+	conn net.Conn
+	dec  *json.Decoder
+}
+
+// Attach subscribes to the running timer's Updates. While a client is
+// attached, a focus session with note prompts on waits for the client to
+// complete it.
+func Attach() (*Subscription, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewEncoder(conn).Encode(Request{Cmd: CmdAttach}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("send %s: %w", CmdAttach, err)
+	}
+	return &Subscription{conn: conn, dec: json.NewDecoder(conn)}, nil
+}
+
+// Next waits for the next Update. It returns io.EOF once the timer has
+// stopped and the last Update has been read.
+func (s *Subscription) Next() (Update, error) {
+	var u Update
+	err := s.dec.Decode(&u)
+	return u, err
+}
+
+// Close detaches, leaving the timer running.
+func (s *Subscription) Close() error {
+	return s.conn.Close()
+}
+
+func dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("unix", SocketPath(), time.Second)
+	if err != nil {
+		return nil, ErrNotRunning
+	}
+	return conn, nil
+}
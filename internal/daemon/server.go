@@ -0,0 +1,101 @@
+package daemon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"time"
+)
+
+// Listen opens the timer socket. A socket file left behind by a timer that
+// died is replaced; a live one is an error.
+func Listen() (net.Listener, error) {
+	path := SocketPath()
+	if _, err := Call(CmdStatus); err == nil {
+		return nil, fmt.Errorf("a timer is already running (socket %s)", path)
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("remove stale socket: %w", err)
+	}
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("listen on %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// Serve answers requests for t on ln until it is closed.
+func Serve(ln net.Listener, t *Timer) error {
+	for {
+		conn, err := ln.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		go handle(conn, t)
+	}
+}
+
+func handle(conn net.Conn, t *Timer) {
+	defer conn.Close()
+
+	var req Request
+	if err := json.NewDecoder(conn).Decode(&req); err != nil {
+		return
+	}
+	if req.Cmd == CmdAttach {
+		stream(conn, t)
+		return
+	}
+
+	var resp response
+	state, err := t.Control(req)
+	if err != nil {
+		resp.Error = err.Error()
+	} else {
+		resp.State = &state
+	}
+	if err := json.NewEncoder(conn).Encode(resp); err != nil {
+		log.Printf("Failed to reply to %s: %v", req.Cmd, err)
+	}
+}
+
+// stream sends Updates to an attached client until the timer stops or the
+// client goes away.
+func stream(conn net.Conn, t *Timer) {
+	updates, detach := t.subscribe()
+	defer detach()
+
+	// Attached clients send nothing more, so a read only returns once the
+	// client has gone.
+	gone := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, conn)
+		close(gone)
+	}()
+
+	enc := json.NewEncoder(conn)
+	send := func(u Update) bool {
+		conn.SetWriteDeadline(time.Now().Add(5 * time.Second))
+		return enc.Encode(u) == nil
+	}
+	if !send(Update{State: t.state()}) {
+		return
+	}
+	for {
+		select {
+		case u, ok := <-updates:
+			if !ok || !send(u) {
+				return
+			}
+		case <-gone:
+			return
+		}
+	}
+}
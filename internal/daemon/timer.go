@@ -0,0 +1,224 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/gen2brain/beeep"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// Timer runs a pomodoro engine for its clients. Sessions are saved as they
+// end, and auto_start_* decides whether the next interval waits for
+// `pom resume`.
+type Timer struct {
+	engine    *pomodoro.Engine
+	startedAt time.Time
+
+	mu     sync.Mutex
+	cfg    config.Config
+	subs   map[chan Update]struct{}
+	closed bool // the engine has stopped sending events
+
+	attached sync.WaitGroup // clients still being sent updates
+}
+
+// NewTimer starts the first interval of cfg's cycle, saving intervals to rec.
+func NewTimer(cfg config.Config, rec pomodoro.Recorder) *Timer {
+	e := pomodoro.NewEngine(cfg, rec, nil)
+	e.Start()
+	return newTimer(cfg, e)
+}
+
+// ResumeTimer continues the interval saved in cp by a timer that died, with
+// cfg as rebuilt for it by the caller.
+func ResumeTimer(cfg config.Config, cp pomodoro.Checkpoint, rec pomodoro.Recorder) *Timer {
+	e := pomodoro.NewEngine(cfg, rec, nil)
+	e.Restore(cp)
+	return newTimer(cfg, e)
+}
+
+func newTimer(cfg config.Config, e *pomodoro.Engine) *Timer {
+	return &Timer{
+		engine:    e,
+		startedAt: e.Now(),
+		cfg:       cfg,
+		subs:      make(map[chan Update]struct{}),
+	}
+}
+
+// Done is closed when the timer stops, either by `pom stop` or because its
+// sequence has run its course.
+func (t *Timer) Done() <-chan struct{} {
+	return t.engine.Done()
+}
+
+// Run advances the timer once a second, sends desktop notifications and
+// keeps attached clients up to date until it stops. If ctx is cancelled
+// first, the current interval is saved as aborted.
+func (t *Timer) Run(ctx context.Context) {
+	events := make(chan struct{})
+	go func() {
+		defer close(events)
+		for ev := range t.engine.Events() {
+			if msg := ev.Notification(t.cfg.Notifications); msg != "" {
+				notify(t.cfg.Notifications.Title, msg)
+			}
+			t.broadcast(&ev)
+		}
+		t.closeSubs()
+	}()
+	defer func() {
+		<-events
+		// Let attached clients see that the timer stopped.
+		t.attached.Wait()
+	}()
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.engine.Done():
+			return
+		case <-ctx.Done():
+			t.engine.Stop()
+			return
+		case <-ticker.C:
+			// A focus session that runs out while a client is attached
+			// waits for the client to ask for its note and complete it.
+			if t.holding() {
+				t.engine.Observe()
+			} else {
+				t.engine.Tick()
+			}
+			t.broadcast(nil)
+		}
+	}
+}
+
+func (t *Timer) holding() bool {
+	s := t.engine.State()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg.NotePrompt && len(t.subs) > 0 && s.Current == pomodoro.Focus
+}
+
+// Control carries out a socket command and returns the state afterwards.
+func (t *Timer) Control(req Request) (State, error) {
+	e := t.engine
+	switch req.Cmd {
+	case CmdPause:
+		e.Pause()
+	case CmdResume:
+		e.Resume()
+	case CmdSkip:
+		e.Skip()
+	case CmdStop:
+		e.Stop()
+	case CmdComplete:
+		if s := e.State(); s.Waiting || (s.Current != pomodoro.Flow && !s.Due) {
+			return State{}, fmt.Errorf("the %s has not reached its end; skip it instead", s.Current)
+		}
+		if req.Text != "" {
+			e.SetNote(req.Text)
+		}
+		e.CompleteAt(req.At)
+	case CmdExtend:
+		if req.Duration <= 0 {
+			return State{}, fmt.Errorf("extend by %s: must be positive", req.Duration)
+		}
+		e.Extend(req.Duration)
+	case CmdRestart:
+		e.Restart()
+	case CmdInterrupt:
+		if req.Kind != pomodoro.Internal && req.Kind != pomodoro.External {
+			return State{}, fmt.Errorf("unknown interruption kind %q", req.Kind)
+		}
+		e.Interrupt(req.Kind, req.Text)
+	case CmdRename:
+		if req.Text == "" {
+			return State{}, fmt.Errorf("rename: name must not be empty")
+		}
+		t.mu.Lock()
+		t.cfg.SessionName = req.Text
+		t.mu.Unlock()
+		e.Rename(req.Text)
+	case CmdStatus:
+	default:
+		return State{}, fmt.Errorf("unknown command %q", req.Cmd)
+	}
+	return t.state(), nil
+}
+
+func (t *Timer) state() State {
+	s := t.engine.State()
+	t.mu.Lock()
+	st := NewState(t.cfg, s)
+	t.mu.Unlock()
+	st.TimerStartedAt = t.startedAt
+	st.At = t.engine.Now()
+	return st
+}
+
+// subscribe returns a channel of Updates for an attached client, closed
+// when the timer stops, and a function that detaches it.
+func (t *Timer) subscribe() (<-chan Update, func()) {
+	ch := make(chan Update, 64)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	t.subs[ch] = struct{}{}
+	t.attached.Add(1)
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			if _, ok := t.subs[ch]; ok {
+				delete(t.subs, ch)
+				close(ch)
+			}
+			t.attached.Done()
+		})
+	}
+}
+
+// broadcast sends the current state and ev, if any, to attached clients. A
+// client too slow to keep up misses updates rather than holding up the
+// timer.
+func (t *Timer) broadcast(ev *pomodoro.Event) {
+	u := Update{State: t.state(), Event: ev}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for ch := range t.subs {
+		select {
+		case ch <- u:
+		default:
+		}
+	}
+}
+
+func (t *Timer) closeSubs() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for ch := range t.subs {
+		delete(t.subs, ch)
+		close(ch)
+	}
+}
+
+func notify(title, message string) {
+	if err := beeep.Notify(title, message, ""); err != nil {
+		log.Printf("Failed to send notification: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package pomodoro
+
+import (
+	"context"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+)
+
+// CheckpointInterval is how often a running engine refreshes its checkpoint
+// between changes, bounding the time a crash can lose.
+const CheckpointInterval = 30 * time.Second
+
+// Checkpointer keeps a copy of the active interval so a timer that dies can
+// be recovered. An engine whose Recorder is also a Checkpointer saves a
+// checkpoint whenever it changes and clears it when it stops.
+type Checkpointer interface {
+	SaveCheckpoint(ctx context.Context, cp Checkpoint) error
+	ClearCheckpoint(ctx context.Context) error
+}
+
+// Checkpoint is the state of an engine's active interval. SavedAt is the
+// last time the engine was known to be alive.
+type Checkpoint struct {
+	Run           RunConfig      `json:"run"`
+	Cycle         Cycle          `json:"cycle"`
+	Total         time.Duration  `json:"total"`
+	Extended      time.Duration  `json:"extended,omitempty"`
+	StartedAt     time.Time      `json:"startedAt"`
+	Target        time.Time      `json:"target"`
+	PausedAt      time.Time      `json:"pausedAt,omitzero"`
+	Pauses        []Pause        `json:"pauses,omitempty"`
+	Interruptions []Interruption `json:"interruptions,omitempty"`
+	Note          string         `json:"note,omitempty"`
+	Waiting       bool           `json:"waiting,omitempty"`
+	WaitingSince  time.Time      `json:"waitingSince,omitzero"`
+	Waited        time.Duration  `json:"waited,omitempty"`
+	SavedAt       time.Time      `json:"savedAt"`
+}
+
+// RunConfig is the part of a timer's config that a checkpoint keeps: what
+// its sessions are recorded as and the rhythm they follow. Everything else,
+// such as notifications and the suspend policy, comes from the config in
+// force when the timer is resumed.
+type RunConfig struct {
+	Name           string        `json:"name,omitempty"`
+	Profile        string        `json:"profile,omitempty"`
+	Project        string        `json:"project,omitempty"`
+	Tags           []string      `json:"tags,omitempty"`
+	Focus          time.Duration `json:"focus"`
+	ShortBreak     time.Duration `json:"shortBreak"`
+	LongBreak      time.Duration `json:"longBreak"`
+	SessionsToLong int           `json:"sessionsToLong"`
+	Flow           bool          `json:"flow,omitempty"`
+	Sequence       string        `json:"sequence,omitempty"` // the step is kept in the Cycle
+}
+
+// NewRunConfig returns the part of cfg a checkpoint keeps.
+func NewRunConfig(cfg config.Config) RunConfig {
+	return RunConfig{
+		Name:           cfg.SessionName,
+		Profile:        cfg.Profile,
+		Project:        cfg.Project,
+		Tags:           slices.Clone(cfg.Tags),
+		Focus:          cfg.SessionDuration,
+		ShortBreak:     cfg.ShortBreak,
+		LongBreak:      cfg.LongBreak,
+		SessionsToLong: cfg.SessionsToLong,
+		Flow:           cfg.FlowMode,
+		Sequence:       cfg.Sequence,
+	}
+}
+
+// Apply sets the fields of cfg that r keeps.
+func (r RunConfig) Apply(cfg *config.Config) {
+	cfg.SessionName = r.Name
+	cfg.Profile = r.Profile
+	cfg.Project = r.Project
+	cfg.Tags = slices.Clone(r.Tags)
+	cfg.SessionDuration = r.Focus
+	cfg.ShortBreak = r.ShortBreak
+	cfg.LongBreak = r.LongBreak
+	cfg.SessionsToLong = r.SessionsToLong
+	cfg.FlowMode = r.Flow
+	cfg.Sequence = r.Sequence
+}
+
+// Due reports whether the countdown in cp would have run out by now had the
+// timer kept running.
+func (cp Checkpoint) Due(now time.Time) bool {
+	return !cp.Waiting && cp.PausedAt.IsZero() && cp.Cycle.Current != Flow && !now.Before(cp.Target)
+}
+
+// Elapsed returns the net time spent in the interval when cp was saved.
+func (cp Checkpoint) Elapsed() time.Duration {
+	if cp.Waiting {
+		return 0
+	}
+	e := Engine{}
+	e.load(cp)
+	return e.elapsed(cp.SavedAt)
+}
+
+// Result returns the interval in cp as a finished session with status:
+// completed at its planned end, or otherwise ended when cp was saved. It
+// returns false when there is nothing to record, because the interval never
+// started or ran for under a second.
+func (cp Checkpoint) Result(status SessionStatus) (SessionResult, bool) {
+	if cp.Waiting {
+		return SessionResult{}, false
+	}
+	e := Engine{}
+	cp.Run.Apply(&e.cfg)
+	e.load(cp)
+	end := cp.SavedAt
+	if status == Completed && cp.Due(cp.Target) {
+		end = cp.Target
+	}
+	r := e.result(end, status)
+	if r == nil {
+		return SessionResult{}, false
+	}
+	return *r, true
+}
+
+// Restore continues the interval saved in cp instead of starting afresh;
+// the engine should have been created with a config cp.Run was applied to.
+// The time between cp
+// being saved and now counts as a pause, so a running countdown picks up
+// where it left off.
+func (e *Engine) Restore(cp Checkpoint) {
+	e.do(func(now time.Time) {
+		if e.started {
+			return
+		}
+		e.started = true
+		e.load(cp)
+		if !cp.Waiting && cp.PausedAt.IsZero() && now.After(cp.SavedAt) {
+			e.pauses = append(e.pauses, Pause{StartedAt: cp.SavedAt, EndedAt: now})
+			e.target = e.target.Add(now.Sub(cp.SavedAt))
+		}
+		kind := IntervalStarted
+		if e.waiting {
+			kind = IntervalReady
+		}
+		e.emit(Event{Kind: kind, At: now, Type: e.cycle.Current, Duration: e.total})
+	})
+}
+
+func (e *Engine) load(cp Checkpoint) {
+	e.cycle = cp.Cycle
+	e.total = cp.Total
+	e.extended = cp.Extended
+	e.start = cp.StartedAt
+	e.target = cp.Target
+	e.pausedAt = cp.PausedAt
+	e.pauses = slices.Clone(cp.Pauses)
+	e.interruptions = slices.Clone(cp.Interruptions)
+	e.note = cp.Note
+	e.waiting = cp.Waiting
+	e.waitingSince = cp.WaitingSince
+	e.waited = cp.Waited
+}
+
+// checkpoint saves the engine's state at now, or clears it once the engine
+// has stopped.
+func (e *Engine) checkpoint(now time.Time) {
+	if e.checkpointer == nil || !e.started {
+		return
+	}
+	e.checkpointed = now
+	if e.finished {
+		if err := e.checkpointer.ClearCheckpoint(context.Background()); err != nil {
+			log.Printf("Failed to clear checkpoint: %v", err)
+		}
+		return
+	}
+	cp := Checkpoint{
+		Run:           NewRunConfig(e.cfg),
+		Cycle:         e.cycle,
+		Total:         e.total,
+		Extended:      e.extended,
+		StartedAt:     e.start,
+		Target:        e.target,
+		PausedAt:      e.pausedAt,
+		Pauses:        slices.Clone(e.pauses),
+		Interruptions: slices.Clone(e.interruptions),
+		Note:          e.note,
+		Waiting:       e.waiting,
+		WaitingSince:  e.waitingSince,
+		Waited:        e.waited,
+		SavedAt:       now,
+	}
+	if err := e.checkpointer.SaveCheckpoint(context.Background(), cp); err != nil {
+		log.Printf("Failed to save checkpoint: %v", err)
+	}
+}
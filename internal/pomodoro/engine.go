@@ -0,0 +1,568 @@
+package pomodoro
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+)
+
+// Clock tells the engine the time. The engine never reads the time any other
+// way, so tests can drive it with a fake clock.
+type Clock interface {
+	// Now returns the wall clock time.
+	Now() time.Time
+	// Monotonic returns how long the clock has been running. Unlike the
+	// wall clock it is not moved by setting the time. Suspend is detected
+	// only if it also stands still while the computer sleeps.
+	Monotonic() time.Duration
+}
+
+type systemClock struct{}
+
+// bootTime anchors the system clock's monotonic readings.
+var bootTime = time.Now()
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// Monotonic uses the monotonic reading Go keeps with time.Now. Only on Linux,
+// where that is CLOCK_MONOTONIC, does it stop during suspend; on macOS and
+// Windows it keeps counting, so sleep goes unnoticed there and the suspend
+// policy never applies.
+func (systemClock) Monotonic() time.Duration { return time.Since(bootTime) }
+
+// SystemClock is the wall clock.
+var SystemClock Clock = systemClock{}
+
+// Recorder persists intervals as they end. storage.Store is a Recorder.
+type Recorder interface {
+	SaveSession(ctx context.Context, s SessionResult) error
+}
+
+// EventKind identifies what happened to a running engine.
+type EventKind string
+
+const (
+	IntervalStarted   EventKind = "interval-started"
+	IntervalReady     EventKind = "interval-ready" // chosen, but waiting for Resume because auto_start_* is off
+	IntervalCompleted EventKind = "interval-completed"
+	IntervalSkipped   EventKind = "interval-skipped"
+	IntervalAborted   EventKind = "interval-aborted"
+	Suspended         EventKind = "suspended" // the timer did not tick for Duration, see config.Suspend
+	Paused            EventKind = "paused"
+	Resumed           EventKind = "resumed"
+	Extended          EventKind = "extended"
+	Finished          EventKind = "finished" // the active sequence has run its course
+	Stopped           EventKind = "stopped"
+)
+
+// Event is sent on the engine's event channel. Type is the interval the
+// event concerns. For IntervalStarted, IntervalReady and Finished, After is
+// how the previous interval ended, and empty for the first one. Result is
+// set on the interval-ending events when the interval was long enough to
+// record and, if the engine has a Recorder, was saved.
+type Event struct {
+	Kind     EventKind      `json:"kind"`
+	At       time.Time      `json:"at"`
+	Type     SessionType    `json:"type"`
+	Duration time.Duration  `json:"duration,omitempty"` // planned length of a started or ready interval
+	After    SessionStatus  `json:"after,omitempty"`
+	Result   *SessionResult `json:"result,omitempty"`
+}
+
+// Notification returns the desktop notification text for ev, or "" if it
+// calls for none. Moving on after a completed interval and the end of a
+// sequence are announced; skips, restarts and stops are not.
+func (ev Event) Notification(n config.Notifications) string {
+	if ev.After != Completed {
+		return ""
+	}
+	switch {
+	case ev.Kind == Finished:
+		return n.Finished
+	case ev.Kind != IntervalStarted && ev.Kind != IntervalReady:
+		return ""
+	}
+	switch ev.Type {
+	case ShortBreak:
+		return n.ShortBreak
+	case LongBreak:
+		return n.LongBreak
+	case Focus, Flow:
+		return n.Focus
+	}
+	return fmt.Sprintf("Up next: %s (%s)", ev.Type, ev.Duration)
+}
+
+// State is a snapshot of an engine. Remaining is zero for flow sessions,
+// which have no target, and the full planned length while Waiting.
+type State struct {
+	Cycle
+	Total         time.Duration // planned length, including Extended
+	Extended      time.Duration
+	StartedAt     time.Time
+	Paused        bool
+	Waiting       bool
+	Due           bool // the countdown has run out, see Due
+	Done          bool
+	Remaining     time.Duration
+	Elapsed       time.Duration // net of pauses
+	Interruptions []Interruption
+}
+
+// Engine runs the cycle of sessions and breaks described by a config. It
+// owns all timing: the TUI, the daemon and tests drive it through its
+// methods and follow it through Events. An Engine is safe for concurrent
+// use.
+type Engine struct {
+	cfg    config.Config
+	rec    Recorder
+	clock  Clock
+	events chan Event
+	done   chan struct{}
+
+	mu            sync.Mutex
+	started       bool
+	finished      bool
+	cycle         Cycle
+	total         time.Duration
+	extended      time.Duration
+	start         time.Time // start of the current interval
+	target        time.Time
+	pausedAt      time.Time // zero while running
+	pauses        []Pause
+	interruptions []Interruption
+	note          string
+	waiting       bool
+	waitingSince  time.Time
+	waited        time.Duration // idle gap before the current interval, including skipped waits
+	pending       []Event       // emitted under mu, sent once it is released
+	checkpointer  Checkpointer
+	checkpointed  time.Time     // when the last checkpoint was saved
+	lastTick      time.Time     // when observe last ran
+	lastMono      time.Duration // the clock's Monotonic reading then
+
+	sendMu sync.Mutex // keeps events in order between callers
+}
+
+// NewEngine returns an engine for cfg that saves intervals to rec, which may
+// be nil, and reads the time from clock, SystemClock if nil. It does nothing
+// until Start or Restore.
+func NewEngine(cfg config.Config, rec Recorder, clock Clock) *Engine {
+	if clock == nil {
+		clock = SystemClock
+	}
+	e := &Engine{
+		cfg:    cfg,
+		rec:    rec,
+		clock:  clock,
+		events: make(chan Event, 64),
+		done:   make(chan struct{}),
+	}
+	e.checkpointer, _ = rec.(Checkpointer)
+	return e
+}
+
+// Events delivers what happens to the engine, in order, and is closed after
+// Finished or Stopped. It must be drained: the engine blocks once its
+// buffer is full.
+func (e *Engine) Events() <-chan Event {
+	return e.events
+}
+
+// Done is closed when the engine stops, either by Stop or because its
+// sequence has run its course.
+func (e *Engine) Done() <-chan struct{} {
+	return e.done
+}
+
+// do runs fn under the lock at the current time, checkpoints the result and
+// then sends the events it emitted.
+func (e *Engine) do(fn func(now time.Time)) {
+	e.run(true, fn)
+}
+
+// run is do, checkpointing only if fn emitted events or the last checkpoint
+// is older than CheckpointInterval unless changed is set. Gaps in the clock
+// are observed first, so fn never acts on time the computer spent asleep.
+func (e *Engine) run(changed bool, fn func(now time.Time)) {
+	e.mu.Lock()
+	if !e.finished {
+		now := e.now()
+		e.observe(now)
+		fn(now)
+		if changed || len(e.pending) > 0 || now.Sub(e.checkpointed) >= CheckpointInterval {
+			e.checkpoint(now)
+		}
+	}
+	pending, finished := e.pending, e.finished
+	e.pending = nil
+	e.sendMu.Lock()
+	e.mu.Unlock()
+	defer e.sendMu.Unlock()
+
+	for _, ev := range pending {
+		e.events <- ev
+	}
+	if finished && len(pending) > 0 {
+		close(e.events)
+	}
+}
+
+// now returns the wall clock time. The engine keeps its times on the wall
+// clock, so that they agree with the times it saves.
+func (e *Engine) now() time.Time {
+	return e.clock.Now().Round(0)
+}
+
+// Now returns the time on the engine's clock, for callers that note times
+// they later hand back to the engine.
+func (e *Engine) Now() time.Time {
+	return e.now()
+}
+
+func (e *Engine) emit(ev Event) {
+	e.pending = append(e.pending, ev)
+}
+
+// Start begins the first interval. Calling it again does nothing.
+func (e *Engine) Start() {
+	e.do(func(now time.Time) {
+		if e.started {
+			return
+		}
+		e.started = true
+		first, d := FirstSession(e.cfg)
+		e.cycle = first
+		e.begin(now, d)
+		e.emit(Event{Kind: IntervalStarted, At: now, Type: first.Current, Duration: d})
+	})
+}
+
+// Pause freezes the countdown. Intervals waiting to start cannot be paused.
+func (e *Engine) Pause() {
+	e.do(func(now time.Time) {
+		if !e.started || e.waiting || !e.pausedAt.IsZero() {
+			return
+		}
+		e.pausedAt = now
+		e.emit(Event{Kind: Paused, At: now, Type: e.cycle.Current})
+	})
+}
+
+// Resume restarts a paused countdown, recording the pause, or starts an
+// interval that is waiting to start.
+func (e *Engine) Resume() {
+	e.do(func(now time.Time) {
+		switch {
+		case e.waiting:
+			waited := e.waited + now.Sub(e.waitingSince)
+			e.begin(now, e.total)
+			e.waited = waited
+			e.emit(Event{Kind: IntervalStarted, At: now, Type: e.cycle.Current, Duration: e.total})
+		case !e.pausedAt.IsZero():
+			e.target = e.target.Add(now.Sub(e.pausedAt))
+			e.pauses = append(e.pauses, Pause{StartedAt: e.pausedAt, EndedAt: now})
+			e.pausedAt = time.Time{}
+			e.emit(Event{Kind: Resumed, At: now, Type: e.cycle.Current})
+		}
+	})
+}
+
+// Skip ends the current interval as skipped and moves on to the next. An
+// interval that was waiting to start has not run, so nothing is recorded;
+// the time spent waiting for it counts as idle time before the next one.
+func (e *Engine) Skip() {
+	e.do(func(now time.Time) {
+		if e.started {
+			e.advance(now, Skipped)
+		}
+	})
+}
+
+// Complete ends the current interval as completed now and moves on to the
+// next. It is how flow sessions end.
+func (e *Engine) Complete() {
+	e.CompleteAt(time.Time{})
+}
+
+// CompleteAt is Complete with the interval ending at at instead of now, for
+// when the end was noticed earlier than it is acted on. A zero at means now.
+func (e *Engine) CompleteAt(at time.Time) {
+	e.do(func(now time.Time) {
+		if !e.started || e.waiting {
+			return
+		}
+		if !at.IsZero() && at.Before(now) {
+			now = at
+		}
+		e.advance(now, Completed)
+	})
+}
+
+// Tick observes the clock and completes the current interval if its
+// countdown has run out. Callers should tick about once a second.
+func (e *Engine) Tick() {
+	e.run(false, func(now time.Time) {
+		if e.due(now) {
+			e.advance(now, Completed)
+		}
+	})
+}
+
+// Observe is Tick without completing anything, for callers that decide
+// themselves when to complete a due interval but still need gaps in the
+// clock noticed.
+func (e *Engine) Observe() {
+	e.run(false, func(time.Time) {})
+}
+
+// Due reports whether the current countdown has run out, for callers that
+// decide themselves when to complete it.
+func (e *Engine) Due() bool {
+	e.Observe()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return !e.finished && e.due(e.now())
+}
+
+func (e *Engine) due(now time.Time) bool {
+	return e.started && !e.waiting && e.pausedAt.IsZero() && e.cycle.Current != Flow && now.After(e.target)
+}
+
+// Restart ends the current interval as aborted and runs it again from its
+// configured length, without extensions.
+func (e *Engine) Restart() {
+	e.do(func(now time.Time) {
+		if !e.started || e.waiting {
+			return
+		}
+		d := e.total - e.extended
+		e.end(now, Aborted)
+		e.begin(now, d)
+		e.emit(Event{Kind: IntervalStarted, At: now, Type: e.cycle.Current, Duration: d, After: Aborted})
+	})
+}
+
+// Extend lengthens the current interval by d. Flow sessions have no target
+// to extend.
+func (e *Engine) Extend(d time.Duration) {
+	e.do(func(now time.Time) {
+		if !e.started || e.cycle.Current == Flow {
+			return
+		}
+		e.total += d
+		e.extended += d
+		e.target = e.target.Add(d)
+		e.emit(Event{Kind: Extended, At: now, Type: e.cycle.Current, Duration: e.total})
+	})
+}
+
+// Stop ends the current interval as aborted and shuts the engine down.
+func (e *Engine) Stop() {
+	e.do(func(now time.Time) {
+		if e.started {
+			e.end(now, Aborted)
+		}
+		e.emit(Event{Kind: Stopped, At: now, Type: e.cycle.Current})
+		e.shutdown()
+	})
+}
+
+// Interrupt logs an interruption in the current interval. It does nothing
+// unless an interval is running.
+func (e *Engine) Interrupt(kind InterruptionKind, note string) {
+	e.do(func(now time.Time) {
+		if !e.started || e.waiting {
+			return
+		}
+		e.interruptions = append(e.interruptions, Interruption{Kind: kind, At: now, Note: note})
+	})
+}
+
+// SetNote sets the note saved with the current interval.
+func (e *Engine) SetNote(note string) {
+	e.do(func(time.Time) { e.note = note })
+}
+
+// Rename changes the name saved with this and later intervals.
+func (e *Engine) Rename(name string) {
+	e.do(func(time.Time) { e.cfg.SessionName = name })
+}
+
+// State returns a snapshot of the engine now.
+func (e *Engine) State() State {
+	e.Observe()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	s := State{
+		Cycle:         e.cycle,
+		Total:         e.total,
+		Extended:      e.extended,
+		StartedAt:     e.start,
+		Paused:        !e.pausedAt.IsZero(),
+		Waiting:       e.waiting,
+		Due:           !e.finished && e.due(now),
+		Done:          e.finished,
+		Interruptions: slices.Clone(e.interruptions),
+	}
+	switch {
+	case e.waiting:
+		s.Remaining = e.total
+	case s.Paused:
+		s.Remaining = e.target.Sub(e.pausedAt)
+		s.Elapsed = e.elapsed(e.pausedAt)
+	default:
+		s.Remaining = e.target.Sub(now)
+		s.Elapsed = e.elapsed(now)
+	}
+	if e.cycle.Current == Flow || s.Remaining < 0 {
+		s.Remaining = 0
+	}
+	return s
+}
+
+func (e *Engine) shutdown() {
+	e.finished = true
+	close(e.done)
+}
+
+// begin starts a countdown of d for the current interval at now.
+func (e *Engine) begin(now time.Time, d time.Duration) {
+	e.total = d
+	e.extended = 0
+	e.start = now
+	e.target = now.Add(d)
+	e.pausedAt = time.Time{}
+	e.pauses = nil
+	e.interruptions = nil
+	e.note = ""
+	e.waiting = false
+	e.waited = 0
+}
+
+// advance ends the current interval with status and moves on to the next,
+// which waits for Resume if auto-start is off for its type. An interval
+// completed after its countdown ran out is recorded as ending on time. The
+// engine shuts down once the active sequence is over.
+func (e *Engine) advance(now time.Time, status SessionStatus) {
+	end := now
+	if status == Completed && e.due(now) {
+		end = e.target
+	}
+	elapsed := e.elapsed(end)
+	var idle time.Duration
+	if e.waiting {
+		// Skipping an interval that never started carries the time spent
+		// waiting for it over to the next one.
+		elapsed = 0
+		idle = e.waited + now.Sub(e.waitingSince)
+	}
+	e.end(end, status)
+
+	next, d, ok := NextSession(e.cycle, status, elapsed, now, e.cfg)
+	e.cycle = next
+	if !ok {
+		e.emit(Event{Kind: Finished, At: now, Type: next.Current, After: status})
+		e.shutdown()
+		return
+	}
+
+	e.begin(now, d)
+	e.waited = idle
+	kind := IntervalStarted
+	autoStart := e.cfg.AutoStartFocus
+	if next.IsBreak(e.cfg) {
+		autoStart = e.cfg.AutoStartBreaks
+	}
+	if !autoStart {
+		kind = IntervalReady
+		e.waiting = true
+		e.waitingSince = now
+	}
+	e.emit(Event{Kind: kind, At: now, Type: next.Current, Duration: d, After: status})
+}
+
+// end records the current interval as ending at now with status.
+func (e *Engine) end(now time.Time, status SessionStatus) {
+	kind := IntervalCompleted
+	switch status {
+	case Skipped:
+		kind = IntervalSkipped
+	case Aborted:
+		kind = IntervalAborted
+	}
+	ev := Event{Kind: kind, At: now, Type: e.cycle.Current}
+	if !e.waiting {
+		ev.Result = e.save(now, status)
+	}
+	e.emit(ev)
+}
+
+// save records the current interval as ending at now and returns it, or nil
+// if it was not recorded.
+func (e *Engine) save(now time.Time, status SessionStatus) *SessionResult {
+	sr := e.result(now, status)
+	if sr == nil || e.rec == nil {
+		return sr
+	}
+	if err := e.rec.SaveSession(context.Background(), *sr); err != nil {
+		log.Printf("Failed to save session: %v", err)
+		return nil
+	}
+	return sr
+}
+
+// result returns the current interval as ending at now with status.
+// Intervals with less than a second of net time are not recorded, so result
+// returns nil for them.
+func (e *Engine) result(now time.Time, status SessionStatus) *SessionResult {
+	elapsed := e.elapsed(now)
+	if elapsed < time.Second {
+		return nil
+	}
+	sr := SessionResult{
+		Name:            e.cfg.SessionName,
+		Profile:         e.cfg.Profile,
+		Project:         e.cfg.Project,
+		Tags:            e.cfg.Tags,
+		Note:            e.note,
+		SessionType:     e.cycle.Current,
+		Status:          status,
+		Duration:        int(elapsed.Round(time.Second).Seconds()),
+		PlannedDuration: int(e.total.Seconds()),
+		ExtendedBy:      int(e.extended.Seconds()),
+		IdleBefore:      int(e.waited.Round(time.Second).Seconds()),
+		PausedDuration:  int((now.Sub(e.start) - elapsed).Round(time.Second).Seconds()),
+		StartedAt:       e.start,
+		CompletedAt:     now,
+		Pauses:          e.pausesUntil(now),
+		Interruptions:   e.interruptions,
+	}
+	return &sr
+}
+
+// pausesUntil returns the pauses of the current interval, closing any pause
+// still in progress at now.
+func (e *Engine) pausesUntil(now time.Time) []Pause {
+	pauses := slices.Clone(e.pauses)
+	if !e.pausedAt.IsZero() {
+		pauses = append(pauses, Pause{StartedAt: e.pausedAt, EndedAt: now})
+	}
+	return pauses
+}
+
+// elapsed returns the net time spent in the current interval at now.
+func (e *Engine) elapsed(now time.Time) time.Duration {
+	d := now.Sub(e.start)
+	for _, p := range e.pausesUntil(now) {
+		d -= p.EndedAt.Sub(p.StartedAt)
+	}
+	return d
+}
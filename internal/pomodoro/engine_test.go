@@ -0,0 +1,372 @@
+package pomodoro
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+)
+
+// fakeClock is a Clock that only moves when told to.
+type fakeClock struct {
+	now  time.Time
+	mono time.Duration
+}
+
+func (c *fakeClock) Now() time.Time           { return c.now }
+func (c *fakeClock) Monotonic() time.Duration { return c.mono }
+
+// recorder is a Recorder that keeps what it is given.
+type recorder struct {
+	saved []SessionResult
+}
+
+func (r *recorder) SaveSession(_ context.Context, s SessionResult) error {
+	r.saved = append(r.saved, s)
+	return nil
+}
+
+// step is one thing done to an engine under test.
+type step func(e *Engine, c *fakeClock)
+
+// run advances the clock by d while the computer is awake, then ticks.
+func run(d time.Duration) step {
+	return func(e *Engine, c *fakeClock) {
+		c.now = c.now.Add(d)
+		c.mono += d
+		e.Tick()
+	}
+}
+
+// sleep advances the wall clock by d without the monotonic clock moving, as
+// a suspend does.
+func sleep(d time.Duration) step {
+	return func(e *Engine, c *fakeClock) {
+		c.now = c.now.Add(d)
+	}
+}
+
+func call(fn func(e *Engine)) step {
+	return func(e *Engine, _ *fakeClock) { fn(e) }
+}
+
+var (
+	start   = call((*Engine).Start)
+	pause   = call((*Engine).Pause)
+	resume  = call((*Engine).Resume)
+	skip    = call((*Engine).Skip)
+	restart = call((*Engine).Restart)
+	stop    = call((*Engine).Stop)
+
+	interrupt = call(func(e *Engine) { e.Interrupt(External, "phone") })
+)
+
+// saved is the part of a SessionResult the tests check.
+type saved struct {
+	Type     SessionType
+	Status   SessionStatus
+	Net      time.Duration
+	Paused   time.Duration
+	Planned  time.Duration
+	Extended time.Duration
+	Idle     time.Duration
+
+	Interruptions int
+}
+
+func summarise(r SessionResult) saved {
+	sec := func(n int) time.Duration { return time.Duration(n) * time.Second }
+	return saved{
+		Type:     r.SessionType,
+		Status:   r.Status,
+		Net:      r.Net(),
+		Paused:   sec(r.PausedDuration),
+		Planned:  sec(r.PlannedDuration),
+		Extended: sec(r.ExtendedBy),
+		Idle:     sec(r.IdleBefore),
+
+		Interruptions: len(r.Interruptions),
+	}
+}
+
+func TestEngine(t *testing.T) {
+	const s = time.Second
+
+	sequence := func(cfg *config.Config) {
+		cfg.Sequences = map[string]config.Sequence{
+			"day": {Steps: []config.Step{{Name: "focus", Duration: 10 * time.Minute}, {Name: "break", Duration: 5 * time.Minute}}, Repeat: 1},
+		}
+		cfg.Sequence = "day"
+	}
+	manual := func(cfg *config.Config) {
+		cfg.AutoStartBreaks = false
+		cfg.AutoStartFocus = false
+	}
+
+	tests := []struct {
+		name   string
+		config func(cfg *config.Config)
+		steps  []step
+		saved  []saved
+		// The engine's state once the steps have run.
+		current       SessionType
+		done          int
+		waiting       bool
+		stopped       bool
+		interruptions int
+	}{
+		{
+			name:  "tick to completion",
+			steps: []step{start, run(25*time.Minute + s), run(5*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+			},
+			current: Focus,
+			done:    1,
+		},
+		{
+			name: "long break after four sessions",
+			steps: []step{start,
+				run(25*time.Minute + s), run(5*time.Minute + s),
+				run(25*time.Minute + s), run(5*time.Minute + s),
+				run(25*time.Minute + s), run(5*time.Minute + s),
+				run(25*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: LongBreak,
+			done:    4,
+		},
+		{
+			name:  "pauses are not counted",
+			steps: []step{start, run(10 * time.Minute), pause, run(3 * time.Minute), resume, run(15*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Paused: 3 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+		},
+		{
+			name:  "interruptions are logged against the running interval",
+			steps: []step{start, run(5 * time.Minute), interrupt, pause, interrupt, resume, run(20*time.Minute + s), interrupt},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute, Interruptions: 2},
+			},
+			current:       ShortBreak,
+			done:          1,
+			interruptions: 1,
+		},
+		{
+			name:   "interruptions are ignored unless an interval is running",
+			config: manual,
+			steps:  []step{interrupt, start, run(25*time.Minute + s), interrupt, stop, interrupt},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+			waiting: true,
+			stopped: true,
+		},
+		{
+			name:  "stop while paused",
+			steps: []step{start, run(5 * time.Minute), pause, run(2 * time.Minute), stop},
+			saved: []saved{
+				{Type: Focus, Status: Aborted, Net: 5 * time.Minute, Paused: 2 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: Focus,
+			stopped: true,
+		},
+		{
+			name:  "skip does not count towards the long break",
+			steps: []step{start, run(10 * time.Minute), skip},
+			saved: []saved{
+				{Type: Focus, Status: Skipped, Net: 10 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+		},
+		{
+			name:  "restart runs the interval again",
+			steps: []step{start, run(10 * time.Minute), restart, run(25*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Aborted, Net: 10 * time.Minute, Planned: 25 * time.Minute},
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+		},
+		{
+			name: "extend moves the target",
+			steps: []step{start, run(20 * time.Minute), call(func(e *Engine) { e.Extend(5 * time.Minute) }),
+				run(5*time.Minute + s), run(5 * time.Minute)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 30 * time.Minute, Planned: 30 * time.Minute, Extended: 5 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+		},
+		{
+			name:   "waits to start when auto-start is off",
+			config: manual,
+			steps:  []step{start, run(25*time.Minute + s), run(4 * time.Minute), resume, run(5*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute, Idle: 4 * time.Minute},
+			},
+			current: Focus,
+			done:    1,
+			waiting: true,
+		},
+		{
+			name:   "skipping a wait keeps the idle gap",
+			config: manual,
+			steps:  []step{start, run(25*time.Minute + s), run(3 * time.Minute), skip, run(2 * time.Minute), resume, run(time.Minute), stop},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+				{Type: Focus, Status: Aborted, Net: time.Minute, Planned: 25 * time.Minute, Idle: 5 * time.Minute},
+			},
+			current: Focus,
+			done:    1,
+			stopped: true,
+		},
+		{
+			name:   "sequence finishes after its repeats",
+			config: sequence,
+			steps:  []step{start, run(10*time.Minute + s), run(5*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 10 * time.Minute, Planned: 10 * time.Minute},
+				{Type: ShortBreak, Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+			stopped: true,
+		},
+		{
+			name: "custom steps auto-start by their kind",
+			config: func(cfg *config.Config) {
+				cfg.Sequences = map[string]config.Sequence{
+					"day": {Steps: []config.Step{{Name: "writing", Duration: 10 * time.Minute}, {Name: "walk", Duration: 5 * time.Minute, Break: true}}},
+				}
+				cfg.Sequence = "day"
+				cfg.AutoStartBreaks = true
+				cfg.AutoStartFocus = false
+			},
+			steps: []step{start, run(10*time.Minute + s), run(5*time.Minute + s)},
+			saved: []saved{
+				{Type: "writing", Status: Completed, Net: 10 * time.Minute, Planned: 10 * time.Minute},
+				{Type: "walk", Status: Completed, Net: 5 * time.Minute, Planned: 5 * time.Minute},
+			},
+			current: "writing",
+			waiting: true,
+		},
+		{
+			name:  "sleep pauses the countdown",
+			steps: []step{start, run(5 * time.Minute), sleep(time.Hour), skip},
+			saved: []saved{
+				{Type: Focus, Status: Skipped, Net: 5 * time.Minute, Paused: time.Hour, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+		},
+		{
+			name:   "sleep counts as focus",
+			config: func(cfg *config.Config) { cfg.Suspend.Policy = config.SuspendCount },
+			steps:  []step{start, run(5 * time.Minute), sleep(time.Hour), run(s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+		},
+		{
+			name:   "sleep aborts the interval",
+			config: func(cfg *config.Config) { cfg.Suspend.Policy = config.SuspendAbort },
+			steps:  []step{start, run(5 * time.Minute), sleep(time.Hour), call((*Engine).Observe)},
+			saved: []saved{
+				{Type: Focus, Status: Aborted, Net: 5 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: Focus,
+			waiting: true,
+		},
+		{
+			name:  "clock set back",
+			steps: []step{start, run(5 * time.Minute), sleep(-time.Hour), run(20*time.Minute + s)},
+			saved: []saved{
+				{Type: Focus, Status: Completed, Net: 25 * time.Minute, Planned: 25 * time.Minute},
+			},
+			current: ShortBreak,
+			done:    1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.Default()
+			if tt.config != nil {
+				tt.config(&cfg)
+			}
+			clock := &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}
+			rec := &recorder{}
+			e := NewEngine(cfg, rec, clock)
+			go func() {
+				for range e.Events() {
+				}
+			}()
+
+			for _, st := range tt.steps {
+				st(e, clock)
+			}
+
+			if len(rec.saved) != len(tt.saved) {
+				t.Fatalf("saved %d intervals, want %d: %+v", len(rec.saved), len(tt.saved), rec.saved)
+			}
+			for i, want := range tt.saved {
+				if got := summarise(rec.saved[i]); got != want {
+					t.Errorf("interval %d:\n got %+v\nwant %+v", i, got, want)
+				}
+			}
+
+			state := e.State()
+			if state.Current != tt.current || state.SessionsDone != tt.done || state.Waiting != tt.waiting || state.Done != tt.stopped ||
+				len(state.Interruptions) != tt.interruptions {
+				t.Errorf("state: got %s, %d done, waiting %t, stopped %t, %d interruptions; want %s, %d done, waiting %t, stopped %t, %d interruptions",
+					state.Current, state.SessionsDone, state.Waiting, state.Done, len(state.Interruptions),
+					tt.current, tt.done, tt.waiting, tt.stopped, tt.interruptions)
+			}
+		})
+	}
+}
+
+func TestEngineEvents(t *testing.T) {
+	cfg := config.Default()
+	cfg.AutoStartBreaks = false
+	clock := &fakeClock{now: time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)}
+	e := NewEngine(cfg, nil, clock)
+
+	e.Start()
+	clock.now = clock.now.Add(25*time.Minute + time.Second)
+	clock.mono += 25*time.Minute + time.Second
+	e.Tick()
+	e.Stop()
+
+	var got []EventKind
+	for ev := range e.Events() {
+		got = append(got, ev.Kind)
+	}
+	want := []EventKind{IntervalStarted, IntervalCompleted, IntervalReady, IntervalAborted, Stopped}
+	if len(got) != len(want) {
+		t.Fatalf("events: got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("events: got %v, want %v", got, want)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package pomodoro
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 type SessionType string
 
@@ -8,13 +11,97 @@ const (
 	Focus      SessionType = "Focus Session"
 	ShortBreak SessionType = "Short Break"
 	LongBreak  SessionType = "Long Break"
+	Flow       SessionType = "Flow Session" // open-ended focus, ended by the user
+)
+
+// SessionStatus records how a session or break ended.
+type SessionStatus string
+
+const (
+	Completed SessionStatus = "completed" // ran until its planned end
+	Aborted   SessionStatus = "aborted"   // interrupted, e.g. by quitting
+	Skipped   SessionStatus = "skipped"   // ended early to move to the next interval
+)
+
+// Pause is a span during which the countdown was frozen.
+type Pause struct {
+	StartedAt time.Time `json:"startedAt"`
+	EndedAt   time.Time `json:"endedAt"`
+}
+
+// InterruptionKind distinguishes interruptions that came from the user's own
+// thoughts from those caused by someone else.
+type InterruptionKind string
+
+const (
+	Internal InterruptionKind = "internal"
+	External InterruptionKind = "external"
 )
 
-// SessionResult represents a completed pomodoro session or break.
+// Interruption is an event logged during a session, with an optional note.
+type Interruption struct {
+	Kind InterruptionKind `json:"kind"`
+	At   time.Time        `json:"at"`
+	Note string           `json:"note,omitempty"`
+}
+
+// SessionResult represents a finished pomodoro session or break. Duration is
+// the time actually spent in the interval, excluding pauses; PlannedDuration
+// is the length it was scheduled for, including ExtendedBy seconds added while
+// it ran, and PausedDuration the total time spent paused. IdleBefore is the
+// time pom spent waiting for the user to start the interval.
 type SessionResult struct {
-	Name        string      `json:"name,omitempty"`
-	SessionType SessionType `json:"sessionType"`
-	Duration    int         `json:"durationSeconds"`
-	StartedAt   time.Time   `json:"startedAt"`
-	CompletedAt time.Time   `json:"completedAt"`
+	ID              int64          `json:"id,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	Profile         string         `json:"profile,omitempty"`
+	Project         string         `json:"project,omitempty"`
+	Tags            []string       `json:"tags,omitempty"`
+	Note            string         `json:"note,omitempty"`
+	SessionType     SessionType    `json:"sessionType"`
+	Status          SessionStatus  `json:"status"`
+	Duration        int            `json:"durationSeconds"`
+	PlannedDuration int            `json:"plannedSeconds"`
+	ExtendedBy      int            `json:"extendedSeconds,omitempty"`
+	PausedDuration  int            `json:"pausedSeconds"`
+	IdleBefore      int            `json:"idleSecondsBefore,omitempty"`
+	StartedAt       time.Time      `json:"startedAt"`
+	CompletedAt     time.Time      `json:"completedAt"`
+	Pauses          []Pause        `json:"pauses,omitempty"`
+	Interruptions   []Interruption `json:"interruptions,omitempty"`
+}
+
+// CountInterruptions returns how many interruptions of kind were logged.
+func (r SessionResult) CountInterruptions(kind InterruptionKind) int {
+	n := 0
+	for _, in := range r.Interruptions {
+		if in.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Net returns the time spent in the interval excluding pauses.
+func (r SessionResult) Net() time.Duration {
+	return time.Duration(r.Duration) * time.Second
+}
+
+// Wall returns the wall-clock time from start to end, including pauses.
+func (r SessionResult) Wall() time.Duration {
+	return r.CompletedAt.Sub(r.StartedAt)
+}
+
+// FormatDuration renders d the way pom shows durations, e.g. "1h 40m 0s",
+// "25m 0s" or "45s".
+func FormatDuration(d time.Duration) string {
+	h := int(d.Hours())
+	m := int(d.Minutes()) % 60
+	s := int(d.Seconds()) % 60
+	if h > 0 {
+		return fmt.Sprintf("%dh %dm %ds", h, m, s)
+	}
+	if m > 0 {
+		return fmt.Sprintf("%dm %ds", m, s)
+	}
+	return fmt.Sprintf("%ds", s)
 }
@@ -0,0 +1,72 @@
+package pomodoro
+
+import (
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+)
+
+// observe notices gaps in the clock since it last ran. Wall clock time
+// that passed without the monotonic clock advancing means the computer
+// slept or the clock was set ahead; a gap like that longer than the suspend
+// threshold is handled by the suspend policy. The clock being set back
+// moves the engine's times back with it, so no time is gained or lost.
+func (e *Engine) observe(now time.Time) {
+	mono := e.clock.Monotonic()
+	last, lastMono := e.lastTick, e.lastMono
+	e.lastTick, e.lastMono = now, mono
+	if last.IsZero() || !e.started {
+		return
+	}
+
+	threshold := e.cfg.Suspend.Threshold
+	if threshold <= 0 {
+		threshold = config.Default().Suspend.Threshold
+	}
+	awake := mono - lastMono
+	gap := now.Sub(last) - awake
+	if gap < -threshold {
+		e.shift(gap)
+		return
+	}
+	if gap <= threshold {
+		return
+	}
+
+	from := last.Add(awake)
+	e.emit(Event{Kind: Suspended, At: now, Type: e.cycle.Current, Duration: gap})
+	if e.waiting || !e.pausedAt.IsZero() {
+		return
+	}
+	switch e.cfg.Suspend.Policy {
+	case config.SuspendCount:
+	case config.SuspendAbort:
+		// The interval ends when the gap began; running it again is up to
+		// the user.
+		d := e.total - e.extended
+		e.end(from, Aborted)
+		e.begin(now, d)
+		e.waiting = true
+		e.waitingSince = now
+		e.emit(Event{Kind: IntervalReady, At: now, Type: e.cycle.Current, Duration: d, After: Aborted})
+	default: // config.SuspendPause
+		e.pauses = append(e.pauses, Pause{StartedAt: from, EndedAt: now})
+		e.target = e.target.Add(gap)
+	}
+}
+
+// shift moves the engine's times by d after the wall clock jumped by d.
+func (e *Engine) shift(d time.Duration) {
+	e.start = e.start.Add(d)
+	e.target = e.target.Add(d)
+	if !e.pausedAt.IsZero() {
+		e.pausedAt = e.pausedAt.Add(d)
+	}
+	if !e.waitingSince.IsZero() {
+		e.waitingSince = e.waitingSince.Add(d)
+	}
+	for i := range e.pauses {
+		e.pauses[i].StartedAt = e.pauses[i].StartedAt.Add(d)
+		e.pauses[i].EndedAt = e.pauses[i].EndedAt.Add(d)
+	}
+}
@@ -6,17 +6,95 @@ import (
 	"github.com/zjom/pom/internal/config"
 )
 
-// NextSession returns the next session type and its duration given the current
-// state. It also returns the updated count of completed focus sessions.
-func NextSession(current SessionType, sessionsDone int, cfg config.Config) (SessionType, time.Duration, int) {
-	switch current {
+// Cycle is a run's position in its rhythm of sessions and breaks.
+type Cycle struct {
+	Current      SessionType `json:"current"`
+	SessionsDone int         `json:"sessionsDone"` // completed focus sessions
+	Step         int         `json:"step"`         // index of the current step when running a sequence, counting repeats
+}
+
+// FirstSession returns the cycle position and duration a run starts with.
+func FirstSession(cfg config.Config) (Cycle, time.Duration) {
+	if seq, ok := cfg.ActiveSequence(); ok {
+		return Cycle{Current: StepType(seq.Steps[0].Name)}, seq.Steps[0].Duration
+	}
+	if cfg.FlowMode {
+		return Cycle{Current: Flow}, 0
+	}
+	return Cycle{Current: Focus}, cfg.SessionDuration
+}
+
+// NextSession returns the cycle position and duration of the interval that
+// follows c, given how the current interval ended and its net elapsed time.
+// Skipped or aborted focus sessions are not counted in SessionsDone, so they
+// do not bring the next long break closer. The returned bool is false when an
+// active sequence has run its course, either by exhausting its repeats or by
+// reaching its end time at now.
+func NextSession(c Cycle, status SessionStatus, elapsed time.Duration, now time.Time, cfg config.Config) (Cycle, time.Duration, bool) {
+	if (c.Current == Focus || c.Current == Flow) && status == Completed {
+		c.SessionsDone++
+	}
+
+	if seq, ok := cfg.ActiveSequence(); ok {
+		c.Step++
+		if seq.Repeat > 0 && c.Step >= seq.Repeat*len(seq.Steps) {
+			return c, 0, false
+		}
+		if until, ok := seq.UntilTime(now); ok && !now.Before(until) {
+			return c, 0, false
+		}
+		step := seq.Steps[c.Step%len(seq.Steps)]
+		c.Current = StepType(step.Name)
+		return c, step.Duration, true
+	}
+
+	switch c.Current {
 	case Focus:
-		sessionsDone++
-		if sessionsDone%cfg.SessionsToLong == 0 {
-			return LongBreak, cfg.LongBreak, sessionsDone
+		if status == Completed && c.SessionsDone%cfg.SessionsToLong == 0 {
+			c.Current = LongBreak
+			return c, cfg.LongBreak, true
 		}
-		return ShortBreak, cfg.ShortBreak, sessionsDone
+		c.Current = ShortBreak
+		return c, cfg.ShortBreak, true
+	case Flow:
+		c.Current = ShortBreak
+		return c, FlowBreak(elapsed, cfg.Flow), true
 	default: // ShortBreak, LongBreak
-		return Focus, cfg.SessionDuration, sessionsDone
+		if cfg.FlowMode {
+			c.Current = Flow
+			return c, 0, true
+		}
+		c.Current = Focus
+		return c, cfg.SessionDuration, true
+	}
+}
+
+// FlowBreak returns the break earned by a flow session of the given length.
+func FlowBreak(focus time.Duration, cfg config.Flow) time.Duration {
+	d := time.Duration(float64(focus) * cfg.BreakRatio).Round(time.Second)
+	return min(max(d, cfg.MinBreak), cfg.MaxBreak)
+}
+
+// StepType maps a sequence step name to its session type. Names other than
+// the built-in ones become session types of their own.
+func StepType(name string) SessionType {
+	switch name {
+	case "focus":
+		return Focus
+	case "break", "short-break":
+		return ShortBreak
+	case "long-break":
+		return LongBreak
+	default:
+		return SessionType(name)
+	}
+}
+
+// IsBreak reports whether the current interval of c is a rest interval
+// rather than work. Sequence steps are breaks if their step says so.
+func (c Cycle) IsBreak(cfg config.Config) bool {
+	if seq, ok := cfg.ActiveSequence(); ok {
+		return seq.Steps[c.Step%len(seq.Steps)].Break
 	}
+	return c.Current != Focus && c.Current != Flow
 }
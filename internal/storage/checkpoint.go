@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// ErrNoCheckpoint is returned by LoadCheckpoint when no interval was left
+// unfinished.
+var ErrNoCheckpoint = errors.New("no checkpoint")
+
+// SaveCheckpoint replaces the checkpoint of the active interval. There is
+// at most one, since only one timer runs at a time.
+func (s *SQLiteStore) SaveCheckpoint(ctx context.Context, cp pomodoro.Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx,
+		`INSERT OR REPLACE INTO checkpoint (id, data, saved_at) VALUES (1, ?, ?)`, string(data), cp.SavedAt)
+	return err
+}
+
+func (s *SQLiteStore) LoadCheckpoint(ctx context.Context) (*pomodoro.Checkpoint, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, `SELECT data FROM checkpoint WHERE id = 1`).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoCheckpoint
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cp pomodoro.Checkpoint
+	if err := json.Unmarshal([]byte(data), &cp); err != nil {
+		return nil, fmt.Errorf("decode checkpoint: %w", err)
+	}
+	return &cp, nil
+}
+
+func (s *SQLiteStore) ClearCheckpoint(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM checkpoint`)
+	return err
+}
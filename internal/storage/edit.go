@@ -0,0 +1,303 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+var (
+	// ErrSessionNotFound is returned when no live session has the requested ID.
+	ErrSessionNotFound = errors.New("session not found")
+	// ErrNothingToUndo is returned by Undo when the undo log is empty.
+	ErrNothingToUndo = errors.New("nothing to undo")
+)
+
+// Undo log actions.
+const (
+	ActionEdit   = "edit"
+	ActionDelete = "delete"
+)
+
+// UndoEntry describes a change reverted by Undo.
+type UndoEntry struct {
+	Action    string    `json:"action"`
+	SessionID int64     `json:"sessionId"`
+	At        time.Time `json:"at"`
+}
+
+// sessionFields are the parts of a session that can be edited. Edits record
+// the previous values in the undo log so they can be restored.
+type sessionFields struct {
+	Name        string               `json:"name"`
+	Tags        []string             `json:"tags"`
+	SessionType pomodoro.SessionType `json:"sessionType"`
+	Duration    int                  `json:"durationSeconds"`
+	StartedAt   time.Time            `json:"startedAt"`
+	CompletedAt time.Time            `json:"completedAt"`
+	Span        sessionSpan          `json:"span"`
+}
+
+// sessionSpan is the planned and paused time of a session, which follow
+// from its times.
+type sessionSpan struct {
+	Planned int              `json:"plannedSeconds"`
+	Paused  int              `json:"pausedSeconds"`
+	Pauses  []pomodoro.Pause `json:"pauses"`
+}
+
+func (s *SQLiteStore) GetSession(ctx context.Context, id int64) (*pomodoro.SessionResult, error) {
+	sessions, err := s.ListSessions(ctx, QueryFilter{ID: id})
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	}
+	return &sessions[0], nil
+}
+
+// UpdateSession overwrites the name, tags, type, times, net, planned and
+// paused durations and pauses of the session with ID sr.ID.
+func (s *SQLiteStore) UpdateSession(ctx context.Context, sr pomodoro.SessionResult) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := readFields(ctx, tx, sr.ID)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(before)
+	if err != nil {
+		return err
+	}
+	if err := logUndo(ctx, tx, sr.ID, ActionEdit, string(data)); err != nil {
+		return err
+	}
+
+	after := sessionFields{
+		Name:        sr.Name,
+		Tags:        sr.Tags,
+		SessionType: sr.SessionType,
+		Duration:    sr.Duration,
+		StartedAt:   sr.StartedAt,
+		CompletedAt: sr.CompletedAt,
+		Span: sessionSpan{
+			Planned: sr.PlannedDuration,
+			Paused:  sr.PausedDuration,
+			Pauses:  sr.Pauses,
+		},
+	}
+	if err := writeFields(ctx, tx, sr.ID, after); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// DeleteSession hides the session from all queries. It stays in the database
+// so Undo can bring it back.
+func (s *SQLiteStore) DeleteSession(ctx context.Context, id int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx,
+		`UPDATE sessions SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	}
+	if err := logUndo(ctx, tx, id, ActionDelete, ""); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Undo reverts the most recent edit or delete and removes it from the log.
+func (s *SQLiteStore) Undo(ctx context.Context) (*UndoEntry, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var logID int64
+	var e UndoEntry
+	var before sql.NullString
+	err = tx.QueryRowContext(ctx,
+		`SELECT id, session_id, action, before, created_at FROM undo_log ORDER BY id DESC LIMIT 1`,
+	).Scan(&logID, &e.SessionID, &e.Action, &before, &e.At)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNothingToUndo
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch e.Action {
+	case ActionDelete:
+		if _, err := tx.ExecContext(ctx, `UPDATE sessions SET deleted_at = NULL WHERE id = ?`, e.SessionID); err != nil {
+			return nil, err
+		}
+	case ActionEdit:
+		var f sessionFields
+		if err := json.Unmarshal([]byte(before.String), &f); err != nil {
+			return nil, fmt.Errorf("decode undo entry %d: %w", logID, err)
+		}
+		if err := writeFields(ctx, tx, e.SessionID, f); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("undo entry %d: unknown action %q", logID, e.Action)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM undo_log WHERE id = ?`, logID); err != nil {
+		return nil, err
+	}
+	return &e, tx.Commit()
+}
+
+// Revision is how often a session has been edited, counting edits still in
+// the undo log, and when it last was.
+type Revision struct {
+	Sequence int
+	Modified time.Time
+}
+
+// Revisions returns the Revision of every session with edits in the undo
+// log. Sessions that have never been edited are left out.
+func (s *SQLiteStore) Revisions(ctx context.Context) (map[int64]Revision, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT session_id, created_at FROM undo_log WHERE action = ? ORDER BY id`, ActionEdit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revs := make(map[int64]Revision)
+	for rows.Next() {
+		var id int64
+		var at time.Time
+		if err := rows.Scan(&id, &at); err != nil {
+			return nil, err
+		}
+		revs[id] = Revision{Sequence: revs[id].Sequence + 1, Modified: at}
+	}
+	return revs, rows.Err()
+}
+
+// DatabaseID returns the random ID the database was given when it was
+// created, to tell its sessions from those of other databases.
+func (s *SQLiteStore) DatabaseID(ctx context.Context) (string, error) {
+	var id string
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM meta WHERE key = 'database_id'`).Scan(&id)
+	return id, err
+}
+
+func logUndo(ctx context.Context, tx *sql.Tx, sessionID int64, action, before string) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO undo_log (session_id, action, before, created_at) VALUES (?, ?, NULLIF(?, ''), ?)`,
+		sessionID, action, before, time.Now(),
+	)
+	return err
+}
+
+func readFields(ctx context.Context, tx *sql.Tx, id int64) (*sessionFields, error) {
+	var f sessionFields
+	var st string
+	err := tx.QueryRowContext(ctx,
+		`SELECT name, session_type, duration_seconds, COALESCE(planned_seconds, duration_seconds), paused_seconds,
+		 started_at, completed_at FROM sessions WHERE id = ? AND deleted_at IS NULL`, id,
+	).Scan(&f.Name, &st, &f.Duration, &f.Span.Planned, &f.Span.Paused, &f.StartedAt, &f.CompletedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %d", ErrSessionNotFound, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	f.SessionType = pomodoro.SessionType(st)
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT t.name FROM session_tags st JOIN tags t ON t.id = st.tag_id
+		 WHERE st.session_id = ? ORDER BY t.name`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		f.Tags = append(f.Tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	rows, err = tx.QueryContext(ctx,
+		`SELECT started_at, ended_at FROM pauses WHERE session_id = ? ORDER BY started_at`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p pomodoro.Pause
+		if err := rows.Scan(&p.StartedAt, &p.EndedAt); err != nil {
+			return nil, err
+		}
+		f.Span.Pauses = append(f.Span.Pauses, p)
+	}
+	return &f, rows.Err()
+}
+
+func writeFields(ctx context.Context, tx *sql.Tx, id int64, f sessionFields) error {
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE sessions SET name = ?, session_type = ?, duration_seconds = ?, started_at = ?, completed_at = ?
+		 WHERE id = ?`,
+		f.Name, string(f.SessionType), f.Duration, f.StartedAt, f.CompletedAt, id,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM session_tags WHERE session_id = ?`, id); err != nil {
+		return err
+	}
+	if err := saveTags(ctx, tx, id, f.Tags); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE sessions SET planned_seconds = ?, paused_seconds = ? WHERE id = ?`,
+		f.Span.Planned, f.Span.Paused, id,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM pauses WHERE session_id = ?`, id); err != nil {
+		return err
+	}
+	for _, p := range f.Span.Pauses {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO pauses (session_id, started_at, ended_at) VALUES (?, ?, ?)`,
+			id, p.StartedAt, p.EndedAt,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// sameSession reports the first editable field in which got differs from
+// want, or "" if none does.
+func sameSession(got, want pomodoro.SessionResult) string {
+	switch {
+	case got.Name != want.Name:
+		return "name"
+	case !slices.Equal(got.Tags, want.Tags):
+		return "tags"
+	case got.SessionType != want.SessionType:
+		return "type"
+	case got.Duration != want.Duration:
+		return "duration"
+	case got.PlannedDuration != want.PlannedDuration:
+		return "planned time"
+	case got.PausedDuration != want.PausedDuration:
+		return "paused time"
+	case !got.StartedAt.Equal(want.StartedAt) || !got.CompletedAt.Equal(want.CompletedAt):
+		return "times"
+	case len(got.Pauses) != len(want.Pauses):
+		return "pauses"
+	}
+	for i, p := range got.Pauses {
+		if !p.StartedAt.Equal(want.Pauses[i].StartedAt) || !p.EndedAt.Equal(want.Pauses[i].EndedAt) {
+			return "pauses"
+		}
+	}
+	return ""
+}
+
+func TestEditUndo(t *testing.T) {
+	ctx := context.Background()
+	store := newStore(t)
+
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	original := pomodoro.SessionResult{
+		Name:            "report",
+		Tags:            []string{"admin", "work"},
+		SessionType:     pomodoro.Focus,
+		Status:          pomodoro.Completed,
+		Duration:        1500,
+		PlannedDuration: 1500,
+		PausedDuration:  300,
+		StartedAt:       start,
+		CompletedAt:     start.Add(30 * time.Minute),
+		Pauses: []pomodoro.Pause{
+			{StartedAt: start.Add(10 * time.Minute), EndedAt: start.Add(15 * time.Minute)},
+		},
+	}
+	if err := store.SaveSession(ctx, original); err != nil {
+		t.Fatal(err)
+	}
+	saved, err := store.ListSessions(ctx, QueryFilter{})
+	if err != nil || len(saved) != 1 {
+		t.Fatalf("list: got %d sessions, %v", len(saved), err)
+	}
+	id := saved[0].ID
+
+	// The edit starts the session later, trimming its pause, and replaces
+	// the tags.
+	edited := original
+	edited.ID = id
+	edited.Name = "report draft"
+	edited.Tags = []string{"writing"}
+	edited.Duration = 900
+	edited.PlannedDuration = 900
+	edited.PausedDuration = 180
+	edited.StartedAt = start.Add(12 * time.Minute)
+	edited.Pauses = []pomodoro.Pause{
+		{StartedAt: start.Add(12 * time.Minute), EndedAt: start.Add(15 * time.Minute)},
+	}
+
+	steps := []struct {
+		name   string
+		do     func() error
+		action string // of the undone entry, for undo steps
+		want   *pomodoro.SessionResult
+	}{
+		{name: "edit", do: func() error { return store.UpdateSession(ctx, edited) }, want: &edited},
+		{name: "undo edit", action: ActionEdit, want: &original},
+		{name: "delete", do: func() error { return store.DeleteSession(ctx, id) }},
+		{name: "undo delete", action: ActionDelete, want: &original},
+	}
+	for _, st := range steps {
+		if st.do != nil {
+			if err := st.do(); err != nil {
+				t.Fatalf("%s: %v", st.name, err)
+			}
+		} else {
+			e, err := store.Undo(ctx)
+			if err != nil {
+				t.Fatalf("%s: %v", st.name, err)
+			}
+			if e.Action != st.action || e.SessionID != id {
+				t.Errorf("%s: undid %s of %d, want %s of %d", st.name, e.Action, e.SessionID, st.action, id)
+			}
+		}
+
+		got, err := store.GetSession(ctx, id)
+		if st.want == nil {
+			if !errors.Is(err, ErrSessionNotFound) {
+				t.Errorf("%s: got %v, want ErrSessionNotFound", st.name, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", st.name, err)
+		}
+		if field := sameSession(*got, *st.want); field != "" {
+			t.Errorf("%s: %s differ:\n got %+v\nwant %+v", st.name, field, *got, *st.want)
+		}
+	}
+
+	if _, err := store.Undo(ctx); !errors.Is(err, ErrNothingToUndo) {
+		t.Errorf("undo with an empty log: got %v, want ErrNothingToUndo", err)
+	}
+	if err := store.DeleteSession(ctx, id+1); !errors.Is(err, ErrSessionNotFound) {
+		t.Errorf("delete missing session: got %v, want ErrSessionNotFound", err)
+	}
+}
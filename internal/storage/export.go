@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// tagSep separates tag names packed into one column; tags are free text, so
+// a control character is the only safe choice.
+const tagSep = "\x1f"
+
+// eachBatch is how many sessions EachSession reads before loading their
+// pauses and interruptions.
+const eachBatch = 256
+
+// EachSession calls fn for every session matching f, oldest first, reading
+// rows from the database as it goes instead of collecting them up front.
+// Sessions are read in batches so their tags, pauses and interruptions can
+// be loaded with a few queries per batch. Iteration stops at the first
+// error fn returns.
+func (s *SQLiteStore) EachSession(ctx context.Context, f QueryFilter, fn func(pomodoro.SessionResult) error) error {
+	where, args := buildWhere(f)
+	query := `SELECT id, name, COALESCE(profile, ''),
+		COALESCE((SELECT projects.name FROM projects WHERE projects.id = sessions.project_id), ''),
+		COALESCE((SELECT group_concat(name, '` + tagSep + `') FROM (
+			SELECT t.name FROM session_tags st JOIN tags t ON t.id = st.tag_id
+			WHERE st.session_id = sessions.id ORDER BY t.name)), ''),
+		note, session_type, status, duration_seconds,
+		COALESCE(planned_seconds, duration_seconds), extended_seconds, paused_seconds, idle_seconds,
+		started_at, completed_at FROM sessions WHERE ` + where + ` ORDER BY started_at`
+	if f.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, f.Limit)
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var batch []pomodoro.SessionResult
+	flush := func() error {
+		if err := s.loadChildren(ctx, batch); err != nil {
+			return err
+		}
+		for _, r := range batch {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for rows.Next() {
+		var r pomodoro.SessionResult
+		var tags, st, status string
+		if err := rows.Scan(&r.ID, &r.Name, &r.Profile, &r.Project, &tags, &r.Note, &st, &status, &r.Duration,
+			&r.PlannedDuration, &r.ExtendedBy, &r.PausedDuration, &r.IdleBefore, &r.StartedAt, &r.CompletedAt); err != nil {
+			return err
+		}
+		if tags != "" {
+			r.Tags = strings.Split(tags, tagSep)
+		}
+		r.SessionType = pomodoro.SessionType(st)
+		r.Status = pomodoro.SessionStatus(status)
+		if batch = append(batch, r); len(batch) == eachBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return flush()
+}
+
+// loadChildren fills in the pauses and interruptions of sessions.
+func (s *SQLiteStore) loadChildren(ctx context.Context, sessions []pomodoro.SessionResult) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+	index := make(map[int64]int, len(sessions))
+	args := make([]any, len(sessions))
+	for i, r := range sessions {
+		index[r.ID] = i
+		args[i] = r.ID
+	}
+	ids := strings.TrimSuffix(strings.Repeat("?, ", len(sessions)), ", ")
+	if err := s.loadPauses(ctx, ids, args, index, sessions); err != nil {
+		return fmt.Errorf("load pauses: %w", err)
+	}
+	if err := s.loadInterruptions(ctx, ids, args, index, sessions); err != nil {
+		return fmt.Errorf("load interruptions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when a database was written by a newer version
+// of pom than the running one.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of pom supports")
+
+// migration upgrades the schema by one version.
+type migration struct {
+	description string
+	up          func(ctx context.Context, tx *sql.Tx) error
+}
+
+// migrations are applied in order; the schema version recorded in PRAGMA
+// user_version is the number of migrations applied. Append only: never edit
+// or reorder an entry once released.
+//
+// Databases created before versioning have user_version 0 but may already
+// contain some of these columns, so additions use addColumn, which tolerates
+// columns that already exist.
+var migrations = []migration{
+	{
+		description: "create sessions table",
+		up: execSQL(`
+CREATE TABLE IF NOT EXISTS sessions (
+	id               INTEGER PRIMARY KEY AUTOINCREMENT,
+	name             TEXT,
+	session_type     TEXT NOT NULL,
+	duration_seconds INTEGER NOT NULL,
+	started_at       DATETIME NOT NULL,
+	completed_at     DATETIME NOT NULL
+);`),
+	},
+	{
+		description: "add sessions.profile",
+		up:          addColumn("sessions", "profile", "TEXT"),
+	},
+	{
+		description: "add sessions.status and sessions.planned_seconds",
+		up: chain(
+			addColumn("sessions", "status", "TEXT NOT NULL DEFAULT 'completed'"),
+			addColumn("sessions", "planned_seconds", "INTEGER"),
+		),
+	},
+	{
+		description: "add pauses table and sessions.paused_seconds",
+		up: chain(
+			addColumn("sessions", "paused_seconds", "INTEGER NOT NULL DEFAULT 0"),
+			execSQL(`
+CREATE TABLE IF NOT EXISTS pauses (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	started_at DATETIME NOT NULL,
+	ended_at   DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS pauses_session_id ON pauses(session_id);`),
+		),
+	},
+	{
+		description: "add interruptions table",
+		up: execSQL(`
+CREATE TABLE interruptions (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id  INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	kind        TEXT NOT NULL,
+	note        TEXT NOT NULL DEFAULT '',
+	occurred_at DATETIME NOT NULL
+);
+CREATE INDEX interruptions_session_id ON interruptions(session_id);`),
+	},
+	{
+		description: "add sessions.extended_seconds",
+		up:          addColumn("sessions", "extended_seconds", "INTEGER NOT NULL DEFAULT 0"),
+	},
+	{
+		description: "add sessions.idle_seconds",
+		up:          addColumn("sessions", "idle_seconds", "INTEGER NOT NULL DEFAULT 0"),
+	},
+	{
+		description: "add tags and session_tags tables",
+		up: execSQL(`
+CREATE TABLE tags (
+	id   INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL UNIQUE
+);
+CREATE TABLE session_tags (
+	session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	tag_id     INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+	PRIMARY KEY (session_id, tag_id)
+);
+CREATE INDEX session_tags_tag_id ON session_tags(tag_id);`),
+	},
+	{
+		description: "add projects table and sessions.project_id",
+		up: chain(
+			execSQL(`
+CREATE TABLE projects (
+	id                  INTEGER PRIMARY KEY AUTOINCREMENT,
+	name                TEXT NOT NULL UNIQUE,
+	color               TEXT NOT NULL DEFAULT '',
+	weekly_goal_seconds INTEGER NOT NULL DEFAULT 0,
+	profile             TEXT NOT NULL DEFAULT '',
+	created_at          DATETIME NOT NULL,
+	archived_at         DATETIME
+);`),
+			addColumn("sessions", "project_id", "INTEGER REFERENCES projects(id)"),
+		),
+	},
+	{
+		description: "add sessions.note and sessions_fts full-text index",
+		up: chain(
+			addColumn("sessions", "note", "TEXT NOT NULL DEFAULT ''"),
+			execSQL(`
+CREATE VIRTUAL TABLE sessions_fts USING fts5(name, note, content='sessions', content_rowid='id');
+CREATE TRIGGER sessions_fts_insert AFTER INSERT ON sessions BEGIN
+	INSERT INTO sessions_fts (rowid, name, note) VALUES (new.id, new.name, new.note);
+END;
+CREATE TRIGGER sessions_fts_delete AFTER DELETE ON sessions BEGIN
+	INSERT INTO sessions_fts (sessions_fts, rowid, name, note) VALUES ('delete', old.id, old.name, old.note);
+END;
+CREATE TRIGGER sessions_fts_update AFTER UPDATE OF name, note ON sessions BEGIN
+	INSERT INTO sessions_fts (sessions_fts, rowid, name, note) VALUES ('delete', old.id, old.name, old.note);
+	INSERT INTO sessions_fts (rowid, name, note) VALUES (new.id, new.name, new.note);
+END;
+INSERT INTO sessions_fts (sessions_fts) VALUES ('rebuild');`),
+		),
+	},
+	{
+		description: "add sessions.deleted_at and undo_log table",
+		up: chain(
+			addColumn("sessions", "deleted_at", "DATETIME"),
+			execSQL(`
+CREATE TABLE undo_log (
+	id         INTEGER PRIMARY KEY AUTOINCREMENT,
+	session_id INTEGER NOT NULL REFERENCES sessions(id) ON DELETE CASCADE,
+	action     TEXT NOT NULL,
+	before     TEXT,
+	created_at DATETIME NOT NULL
+);`),
+		),
+	},
+	{
+		description: "add checkpoint table",
+		up: execSQL(`
+CREATE TABLE checkpoint (
+	id       INTEGER PRIMARY KEY CHECK (id = 1),
+	data     TEXT NOT NULL,
+	saved_at DATETIME NOT NULL
+);`),
+	},
+	{
+		description: "add meta table with a random database ID",
+		up: execSQL(`
+CREATE TABLE meta (
+	key   TEXT PRIMARY KEY,
+	value TEXT NOT NULL
+);
+INSERT INTO meta (key, value) VALUES ('database_id', lower(hex(randomblob(16))));`),
+	},
+}
+
+// SchemaVersion is the schema version this build of pom reads and writes.
+var SchemaVersion = len(migrations)
+
+// migrate brings db up to SchemaVersion, applying each pending migration in
+// its own transaction. An existing database is first backed up next to
+// dbPath, so a failed or unwanted upgrade can be rolled back by hand.
+func migrate(ctx context.Context, db *sql.DB, dbPath string) error {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	if version > SchemaVersion {
+		return fmt.Errorf("%w: %s is at version %d, this pom supports up to %d; upgrade pom",
+			ErrSchemaTooNew, dbPath, version, SchemaVersion)
+	}
+
+	if version == SchemaVersion {
+		return nil
+	}
+
+	// A brand-new file has nothing worth backing up.
+	var tables int
+	if err := db.QueryRowContext(ctx, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'").Scan(&tables); err != nil {
+		return fmt.Errorf("read schema: %w", err)
+	}
+	if tables > 0 {
+		if err := backup(ctx, db, dbPath, version); err != nil {
+			return fmt.Errorf("back up database before upgrading from version %d: %w", version, err)
+		}
+	}
+
+	for i := version; i < SchemaVersion; i++ {
+		m := migrations[i]
+		if err := apply(ctx, db, i+1, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", i+1, m.description, err)
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, db *sql.DB, version int, m migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(ctx, tx); err != nil {
+		return err
+	}
+	// PRAGMA does not accept bound parameters.
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PRAGMA user_version = %d", version)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// backup writes a consistent copy of the database next to dbPath, named after
+// the schema version it was taken at.
+func backup(ctx context.Context, db *sql.DB, dbPath string, version int) error {
+	if dbPath == "" || dbPath == ":memory:" {
+		return nil
+	}
+	dest := fmt.Sprintf("%s.v%d-%s.bak", dbPath, version, time.Now().Format("20060102-150405"))
+	_, err := db.ExecContext(ctx, "VACUUM INTO ?", dest)
+	return err
+}
+
+func execSQL(stmt string) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, stmt)
+		return err
+	}
+}
+
+func chain(steps ...func(context.Context, *sql.Tx) error) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		for _, step := range steps {
+			if err := step(ctx, tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumn adds column to table unless it already exists.
+func addColumn(table, column, decl string) func(context.Context, *sql.Tx) error {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		var n int
+		err := tx.QueryRowContext(ctx,
+			"SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column,
+		).Scan(&n)
+		if err != nil || n > 0 {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, decl))
+		return err
+	}
+}
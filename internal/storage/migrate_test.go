@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// oldDB creates a database at schema version, runs seed against it and
+// returns its path.
+func oldDB(t *testing.T, version int, seed string) string {
+	t.Helper()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "history.db")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := range version {
+		if err := apply(ctx, db, i+1, migrations[i]); err != nil {
+			t.Fatalf("migration %d: %v", i+1, err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, seed); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	return path
+}
+
+func TestMigrateOldDatabase(t *testing.T) {
+	start := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	row := func(columns, values string) string {
+		return `INSERT INTO sessions (name, session_type, duration_seconds, started_at, completed_at` + columns + `)
+			VALUES ('old work', 'Focus Session', 1500, '` + start.Format("2006-01-02 15:04:05Z07:00") + `', '` +
+			start.Add(25*time.Minute).Format("2006-01-02 15:04:05Z07:00") + `'` + values + `)`
+	}
+
+	fts := migrationIndex(t, "sessions_fts")
+	tests := []struct {
+		name    string
+		version int
+		seed    string
+		backup  bool
+	}{
+		{
+			// Databases from before versioning have some later columns
+			// already but user_version 0.
+			name: "unversioned",
+			seed: `CREATE TABLE sessions (
+				id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, profile TEXT, session_type TEXT NOT NULL,
+				duration_seconds INTEGER NOT NULL, started_at DATETIME NOT NULL, completed_at DATETIME NOT NULL);` +
+				row(", profile", ", 'deep'"),
+			backup: true,
+		},
+		{
+			name:    "before full-text search",
+			version: fts,
+			seed:    row(", status", ", 'skipped'"),
+			backup:  true,
+		},
+		{
+			name:    "after full-text search",
+			version: fts + 1,
+			seed:    row(", note", ", 'kept'"),
+			backup:  true,
+		},
+		{
+			name:    "up to date",
+			version: SchemaVersion,
+			seed:    row(", note", ", 'kept'"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := oldDB(t, tt.version, tt.seed)
+
+			store, err := NewSQLiteStore(path)
+			if err != nil {
+				t.Fatalf("open: %v", err)
+			}
+			defer store.Close()
+
+			var version int
+			if err := store.db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+				t.Fatal(err)
+			}
+			if version != SchemaVersion {
+				t.Errorf("version %d, want %d", version, SchemaVersion)
+			}
+
+			sessions, err := store.ListSessions(context.Background(), QueryFilter{})
+			if err != nil {
+				t.Fatalf("list: %v", err)
+			}
+			if len(sessions) != 1 {
+				t.Fatalf("got %d sessions, want 1", len(sessions))
+			}
+			s := sessions[0]
+			if s.Name != "old work" || s.Duration != 1500 || s.PlannedDuration != 1500 || !s.StartedAt.Equal(start) {
+				t.Errorf("session not carried over: %+v", s)
+			}
+			if tt.version == 0 && (s.Profile != "deep" || s.Status != pomodoro.Completed) {
+				t.Errorf("existing columns lost or defaults missing: %+v", s)
+			}
+
+			// The old row must be in the full-text index.
+			found, err := store.ListSessions(context.Background(), QueryFilter{Search: "work"})
+			if err != nil {
+				t.Fatalf("search: %v", err)
+			}
+			if len(found) != 1 {
+				t.Errorf("search found %d sessions, want 1", len(found))
+			}
+
+			backups, err := filepath.Glob(path + ".v*.bak")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := 0
+			if tt.backup {
+				want = 1
+			}
+			if len(backups) != want {
+				t.Errorf("got %d backups %v, want %d", len(backups), backups, want)
+			}
+		})
+	}
+}
+
+func TestMigrateNewerDatabase(t *testing.T) {
+	path := oldDB(t, 0, "PRAGMA user_version = 1000")
+	if _, err := NewSQLiteStore(path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("open: got %v, want ErrSchemaTooNew", err)
+	}
+}
+
+func TestMigrateNewDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store, err := NewSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+	if backups, _ := filepath.Glob(path + ".v*.bak"); len(backups) > 0 {
+		t.Errorf("new database was backed up: %v", backups)
+	}
+}
+
+// migrationIndex returns the index of the migration whose description
+// mentions s.
+func migrationIndex(t *testing.T, s string) int {
+	t.Helper()
+	for i, m := range migrations {
+		if strings.Contains(m.description, s) {
+			return i
+		}
+	}
+	t.Fatalf("no migration mentions %q", s)
+	return 0
+}
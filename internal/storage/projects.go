@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrProjectNotFound is returned when no project has the requested name.
+var ErrProjectNotFound = errors.New("project not found")
+
+// Project groups sessions under a named piece of work with a weekly focus
+// goal. Profile optionally names the timing profile its sessions default to.
+type Project struct {
+	Name       string        `json:"name"`
+	Color      string        `json:"color,omitempty"`
+	WeeklyGoal time.Duration `json:"weeklyGoal"`
+	Profile    string        `json:"profile,omitempty"`
+	CreatedAt  time.Time     `json:"createdAt"`
+	ArchivedAt *time.Time    `json:"archivedAt,omitempty"`
+}
+
+func (s *SQLiteStore) CreateProject(ctx context.Context, p Project) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO projects (name, color, weekly_goal_seconds, profile, created_at) VALUES (?, ?, ?, ?, ?)`,
+		p.Name, p.Color, int64(p.WeeklyGoal.Seconds()), p.Profile, p.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("create project %q: %w", p.Name, err)
+	}
+	return nil
+}
+
+func (s *SQLiteStore) GetProject(ctx context.Context, name string) (*Project, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT name, color, weekly_goal_seconds, profile, created_at, archived_at FROM projects WHERE name = ?`, name)
+	p, err := scanProject(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %q", ErrProjectNotFound, name)
+	}
+	return p, err
+}
+
+func (s *SQLiteStore) ListProjects(ctx context.Context, includeArchived bool) ([]Project, error) {
+	query := `SELECT name, color, weekly_goal_seconds, profile, created_at, archived_at FROM projects`
+	if !includeArchived {
+		query += " WHERE archived_at IS NULL"
+	}
+	query += " ORDER BY name"
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+	return projects, rows.Err()
+}
+
+func (s *SQLiteStore) ArchiveProject(ctx context.Context, name string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE projects SET archived_at = ? WHERE name = ? AND archived_at IS NULL`, at, name)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %q (or already archived)", ErrProjectNotFound, name)
+	}
+	return nil
+}
+
+func scanProject(row interface{ Scan(...any) error }) (*Project, error) {
+	var p Project
+	var goalSeconds int64
+	var archived sql.NullTime
+	if err := row.Scan(&p.Name, &p.Color, &goalSeconds, &p.Profile, &p.CreatedAt, &archived); err != nil {
+		return nil, err
+	}
+	p.WeeklyGoal = time.Duration(goalSeconds) * time.Second
+	if archived.Valid {
+		p.ArchivedAt = &archived.Time
+	}
+	return &p, nil
+}
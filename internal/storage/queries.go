@@ -7,18 +7,67 @@ import (
 )
 
 // QueryFilter constrains which sessions are returned by List or Statistics queries.
+// Sessions match Tags if they carry any of them, or all of them when
+// MatchAllTags is set. Search is free text matched against session names and
+// notes. Deleted sessions never match.
 type QueryFilter struct {
-	Name        string
-	SessionType *pomodoro.SessionType
-	From        *time.Time
-	To          *time.Time
-	Limit       int
+	ID           int64
+	Name         string
+	Profile      string
+	Project      string
+	Tags         []string
+	MatchAllTags bool
+	Search       string
+	SessionType  *pomodoro.SessionType
+	Status       *pomodoro.SessionStatus
+	From         *time.Time
+	To           *time.Time
+	Limit        int
 }
 
-// Statistics holds aggregated session data.
+// Statistics holds aggregated session data. FocusTime is the time actually
+// spent in focus sessions excluding pauses, FocusWallTime the same including
+// them; FlowTime is the net time spent in open-ended flow sessions, which are
+// counted separately from focus sessions. CompletionRate is the fraction of
+// focus sessions that ran to completion. InterruptionsPerFocusHour counts
+// interruptions of any kind against net focus time.
 type Statistics struct {
-	TotalSessions   int            `json:"totalSessions"`
-	TotalTime       time.Duration  `json:"totalTime"`
-	AverageDuration time.Duration  `json:"averageDuration"`
-	ByType          map[string]int `json:"byType"`
+	TotalSessions             int            `json:"totalSessions"`
+	TotalTime                 time.Duration  `json:"totalTime"`
+	AverageDuration           time.Duration  `json:"averageDuration"`
+	FocusTime                 time.Duration  `json:"focusTime"`
+	FocusWallTime             time.Duration  `json:"focusWallTime"`
+	PlannedFocusTime          time.Duration  `json:"plannedFocusTime"`
+	FlowTime                  time.Duration  `json:"flowTime"`
+	PausedTime                time.Duration  `json:"pausedTime"`
+	IdleTime                  time.Duration  `json:"idleTime"`
+	Pauses                    int            `json:"pauses"`
+	InternalInterruptions     int            `json:"internalInterruptions"`
+	ExternalInterruptions     int            `json:"externalInterruptions"`
+	InterruptionsPerFocusHour float64        `json:"interruptionsPerFocusHour"`
+	CompletionRate            float64        `json:"completionRate"`
+	ByType                    map[string]int `json:"byType"`
+	ByStatus                  map[string]int `json:"byStatus"`
+}
+
+// GroupKey selects the session attribute a Breakdown groups by.
+type GroupKey string
+
+const (
+	GroupByType    GroupKey = "type"
+	GroupByProfile GroupKey = "profile"
+	GroupByProject GroupKey = "project"
+	GroupByTag     GroupKey = "tag"
+)
+
+// GroupStats holds aggregated session data for one value of a GroupKey.
+// Focus figures include flow sessions. Goal is filled in by callers that
+// track one, such as the project breakdown.
+type GroupStats struct {
+	Key           string        `json:"key"`
+	TotalSessions int           `json:"totalSessions"`
+	FocusSessions int           `json:"focusSessions"`
+	TotalTime     time.Duration `json:"totalTime"`
+	FocusTime     time.Duration `json:"focusTime"`
+	Goal          time.Duration `json:"goal,omitempty"`
 }
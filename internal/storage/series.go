@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// Period is the bucket size of a time series.
+type Period string
+
+const (
+	PeriodDay     Period = "day"
+	PeriodWeek    Period = "week"
+	PeriodMonth   Period = "month"
+	PeriodWeekday Period = "weekday"
+	PeriodHour    Period = "hour"
+)
+
+// Bucket holds aggregated session data for one period of a time series.
+// Start is the beginning of the period for day, week and month series and
+// zero for weekday and hour-of-day series, which fold all days together.
+// Interruptions counts those logged during the period's sessions.
+type Bucket struct {
+	GroupStats
+	Interruptions int       `json:"interruptions"`
+	Start         time.Time `json:"start,omitzero"`
+}
+
+// MarshalJSON adds focusSeconds and totalSeconds alongside the durations,
+// which encode as nanoseconds, so scripts reading a series needn't convert.
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	type bucket Bucket // without this method
+	return json.Marshal(struct {
+		bucket
+		FocusSeconds int64 `json:"focusSeconds"`
+		TotalSeconds int64 `json:"totalSeconds"`
+	}{bucket(b), int64(b.FocusTime.Seconds()), int64(b.TotalTime.Seconds())})
+}
+
+// GetTimeSeries buckets sessions matching f by the local time in loc at which
+// they started. Day, week (Monday-based) and month series run from the first
+// to the last period with sessions, including empty periods in between;
+// weekday series always have seven buckets starting on Monday and hour series
+// twenty-four.
+//
+// Bucketing is done here rather than in SQL because SQLite has no notion of
+// the user's time zone, and day boundaries must follow its DST changes.
+func (s *SQLiteStore) GetTimeSeries(ctx context.Context, f QueryFilter, period Period, loc *time.Location) ([]Bucket, error) {
+	var fixed []Bucket
+	switch period {
+	case PeriodWeekday:
+		for i := range 7 {
+			fixed = append(fixed, Bucket{GroupStats: GroupStats{Key: time.Weekday((i + 1) % 7).String()[:3]}})
+		}
+	case PeriodHour:
+		for h := range 24 {
+			fixed = append(fixed, Bucket{GroupStats: GroupStats{Key: fmt.Sprintf("%02d:00", h)}})
+		}
+	case PeriodDay, PeriodWeek, PeriodMonth:
+	default:
+		return nil, fmt.Errorf("unsupported period %q", period)
+	}
+
+	// Calendar periods are collected by start time and laid out in order
+	// once the range is known.
+	periods := make(map[int64]*Bucket)
+	var first, last time.Time
+
+	err := s.EachSession(ctx, f, func(sr pomodoro.SessionResult) error {
+		t := sr.StartedAt.In(loc)
+		var g *Bucket
+		switch period {
+		case PeriodWeekday:
+			g = &fixed[(int(t.Weekday())+6)%7]
+		case PeriodHour:
+			g = &fixed[t.Hour()]
+		default:
+			start := periodStart(t, period)
+			if first.IsZero() || start.Before(first) {
+				first = start
+			}
+			if start.After(last) {
+				last = start
+			}
+			if g = periods[start.Unix()]; g == nil {
+				g = &Bucket{}
+				periods[start.Unix()] = g
+			}
+		}
+
+		d := time.Duration(sr.Duration) * time.Second
+		g.TotalSessions++
+		g.TotalTime += d
+		g.Interruptions += len(sr.Interruptions)
+		if sr.SessionType == pomodoro.Focus || sr.SessionType == pomodoro.Flow {
+			g.FocusSessions++
+			g.FocusTime += d
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fixed != nil {
+		return fixed, nil
+	}
+
+	var buckets []Bucket
+	for start := first; len(periods) > 0 && !start.After(last); start = periodAdd(start, period) {
+		b := newBucket(start, period)
+		if g := periods[start.Unix()]; g != nil {
+			b.TotalSessions, b.FocusSessions = g.TotalSessions, g.FocusSessions
+			b.TotalTime, b.FocusTime = g.TotalTime, g.FocusTime
+			b.Interruptions = g.Interruptions
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, nil
+}
+
+func newBucket(start time.Time, period Period) Bucket {
+	var key string
+	switch period {
+	case PeriodDay:
+		key = start.Format("2006-01-02")
+	case PeriodWeek:
+		y, w := start.ISOWeek()
+		key = fmt.Sprintf("%d-W%02d", y, w)
+	case PeriodMonth:
+		key = start.Format("2006-01")
+	}
+	return Bucket{GroupStats: GroupStats{Key: key}, Start: start}
+}
+
+// periodStart returns local midnight at the start of the day, Monday-based
+// week or month containing t, in t's location.
+func periodStart(t time.Time, period Period) time.Time {
+	y, m, d := t.Date()
+	switch period {
+	case PeriodWeek:
+		d -= (int(t.Weekday()) + 6) % 7
+	case PeriodMonth:
+		d = 1
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// periodAdd returns the start of the period after the one starting at t.
+func periodAdd(t time.Time, period Period) time.Time {
+	switch period {
+	case PeriodWeek:
+		return t.AddDate(0, 0, 7)
+	case PeriodMonth:
+		return t.AddDate(0, 1, 0)
+	}
+	return t.AddDate(0, 0, 1)
+}
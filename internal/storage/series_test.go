@@ -0,0 +1,245 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/zjom/pom/internal/pomodoro"
+)
+
+// newStore returns an empty store in a temporary directory.
+func newStore(t *testing.T) *SQLiteStore {
+	t.Helper()
+	store, err := NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// berlin has a DST change at 02:00 on Sunday 29 March 2026, which makes
+// that day 23 hours long.
+func berlin(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestPeriodStart(t *testing.T) {
+	loc := berlin(t)
+	at := func(m time.Month, d, h, min int) time.Time { return time.Date(2026, m, d, h, min, 0, 0, loc) }
+
+	tests := []struct {
+		name   string
+		t      time.Time
+		period Period
+		start  time.Time
+		next   time.Time
+	}{
+		{"day before the change", at(3, 28, 23, 59), PeriodDay, at(3, 28, 0, 0), at(3, 29, 0, 0)},
+		{"short day", at(3, 29, 12, 0), PeriodDay, at(3, 29, 0, 0), at(3, 30, 0, 0)},
+		{"day after the change", at(3, 30, 0, 0), PeriodDay, at(3, 30, 0, 0), at(3, 31, 0, 0)},
+		{"sunday ends the week", at(3, 29, 23, 0), PeriodWeek, at(3, 23, 0, 0), at(3, 30, 0, 0)},
+		{"monday starts the week", at(3, 30, 0, 0), PeriodWeek, at(3, 30, 0, 0), at(4, 6, 0, 0)},
+		{"week across months", at(4, 1, 9, 0), PeriodWeek, at(3, 30, 0, 0), at(4, 6, 0, 0)},
+		{"last minute of the month", at(3, 31, 23, 59), PeriodMonth, at(3, 1, 0, 0), at(4, 1, 0, 0)},
+		{"month end after the 30th", at(1, 31, 12, 0), PeriodMonth, at(1, 1, 0, 0), at(2, 1, 0, 0)},
+		{"february", at(2, 28, 12, 0), PeriodMonth, at(2, 1, 0, 0), at(3, 1, 0, 0)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := periodStart(tt.t, tt.period)
+			if !start.Equal(tt.start) || start.Location() != loc {
+				t.Errorf("start: got %s, want %s", start, tt.start)
+			}
+			if next := periodAdd(start, tt.period); !next.Equal(tt.next) {
+				t.Errorf("next: got %s, want %s", next, tt.next)
+			}
+		})
+	}
+
+	if d := periodAdd(at(3, 29, 0, 0), PeriodDay).Sub(at(3, 29, 0, 0)); d != 23*time.Hour {
+		t.Errorf("29 March lasts %s, want 23h", d)
+	}
+}
+
+func TestGetTimeSeries(t *testing.T) {
+	ctx := context.Background()
+	loc := berlin(t)
+	store := newStore(t)
+
+	// Start times are UTC; the comments give the Berlin time.
+	sessions := []struct {
+		start         time.Time
+		typ           pomodoro.SessionType
+		mins          int
+		interruptions int
+	}{
+		{time.Date(2026, 3, 28, 22, 30, 0, 0, time.UTC), pomodoro.Focus, 25, 1},     // Sat 28th 23:30 CET
+		{time.Date(2026, 3, 28, 23, 30, 0, 0, time.UTC), pomodoro.ShortBreak, 5, 0}, // Sun 29th 00:30 CET
+		{time.Date(2026, 3, 29, 21, 30, 0, 0, time.UTC), pomodoro.Flow, 50, 2},      // Sun 29th 23:30 CEST
+		{time.Date(2026, 3, 29, 22, 30, 0, 0, time.UTC), pomodoro.Focus, 25, 0},     // Mon 30th 00:30 CEST
+		{time.Date(2026, 4, 1, 8, 0, 0, 0, time.UTC), pomodoro.LongBreak, 15, 0},    // Wed 1st 10:00 CEST
+	}
+	for _, s := range sessions {
+		sr := pomodoro.SessionResult{
+			SessionType:     s.typ,
+			Status:          pomodoro.Completed,
+			Duration:        s.mins * 60,
+			PlannedDuration: s.mins * 60,
+			StartedAt:       s.start,
+			CompletedAt:     s.start.Add(time.Duration(s.mins) * time.Minute),
+		}
+		for i := range s.interruptions {
+			sr.Interruptions = append(sr.Interruptions, pomodoro.Interruption{Kind: pomodoro.Internal, At: s.start.Add(time.Duration(i+1) * time.Minute)})
+		}
+		if err := store.SaveSession(ctx, sr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// want is a bucket's key, start, session counts, minutes and
+	// interruptions.
+	type want struct {
+		key                  string
+		start                time.Time
+		total, focus         int
+		totalMins, focusMins int
+		interruptions        int
+	}
+	day := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, loc) }
+
+	tests := []struct {
+		period Period
+		want   []want
+	}{
+		{PeriodDay, []want{
+			{"2026-03-28", day(3, 28), 1, 1, 25, 25, 1},
+			{"2026-03-29", day(3, 29), 2, 1, 55, 50, 2},
+			{"2026-03-30", day(3, 30), 1, 1, 25, 25, 0},
+			{"2026-03-31", day(3, 31), 0, 0, 0, 0, 0},
+			{"2026-04-01", day(4, 1), 1, 0, 15, 0, 0},
+		}},
+		{PeriodWeek, []want{
+			{"2026-W13", day(3, 23), 3, 2, 80, 75, 3},
+			{"2026-W14", day(3, 30), 2, 1, 40, 25, 0},
+		}},
+		{PeriodMonth, []want{
+			{"2026-03", day(3, 1), 4, 3, 105, 100, 3},
+			{"2026-04", day(4, 1), 1, 0, 15, 0, 0},
+		}},
+		{PeriodWeekday, []want{
+			{key: "Mon", total: 1, focus: 1, totalMins: 25, focusMins: 25},
+			{key: "Tue"},
+			{key: "Wed", total: 1, totalMins: 15},
+			{key: "Thu"},
+			{key: "Fri"},
+			{key: "Sat", total: 1, focus: 1, totalMins: 25, focusMins: 25, interruptions: 1},
+			{key: "Sun", total: 2, focus: 1, totalMins: 55, focusMins: 50, interruptions: 2},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			buckets, err := store.GetTimeSeries(ctx, QueryFilter{}, tt.period, loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(buckets) != len(tt.want) {
+				t.Fatalf("got %d buckets, want %d: %+v", len(buckets), len(tt.want), buckets)
+			}
+			for i, w := range tt.want {
+				b := buckets[i]
+				got := want{b.Key, b.Start, b.TotalSessions, b.FocusSessions,
+					int(b.TotalTime.Minutes()), int(b.FocusTime.Minutes()), b.Interruptions}
+				if !got.start.Equal(w.start) {
+					t.Errorf("bucket %d: start %s, want %s", i, got.start, w.start)
+				}
+				got.start = w.start
+				if got != w {
+					t.Errorf("bucket %d:\n got %+v\nwant %+v", i, got, w)
+				}
+			}
+		})
+	}
+
+	hours, err := store.GetTimeSeries(ctx, QueryFilter{}, PeriodHour, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hours) != 24 {
+		t.Fatalf("got %d hour buckets, want 24", len(hours))
+	}
+	for h, n := range map[int]int{0: 2, 10: 1, 23: 2} {
+		if hours[h].TotalSessions != n {
+			t.Errorf("%s: got %d sessions, want %d", hours[h].Key, hours[h].TotalSessions, n)
+		}
+	}
+
+	if _, err := store.GetTimeSeries(ctx, QueryFilter{}, "year", loc); err == nil {
+		t.Error("unsupported period: got no error")
+	}
+
+	empty, err := newStore(t).GetTimeSeries(ctx, QueryFilter{}, PeriodDay, loc)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("empty store: got %d buckets, %v; want none", len(empty), err)
+	}
+}
+
+func TestBucketJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		bucket Bucket
+		want   map[string]any
+	}{
+		{
+			name: "calendar period",
+			bucket: Bucket{
+				GroupStats:    GroupStats{Key: "2026-03-29", TotalSessions: 2, FocusSessions: 1, TotalTime: 55*time.Minute + 30*time.Second, FocusTime: 50 * time.Minute},
+				Interruptions: 2,
+				Start:         time.Date(2026, 3, 29, 0, 0, 0, 0, time.UTC),
+			},
+			want: map[string]any{
+				"key": "2026-03-29", "totalSessions": 2.0, "focusSessions": 1.0,
+				"totalTime": 3330e9, "focusTime": 3000e9, "interruptions": 2.0,
+				"start": "2026-03-29T00:00:00Z", "focusSeconds": 3000.0, "totalSeconds": 3330.0,
+			},
+		},
+		{
+			name:   "weekday bucket has no start",
+			bucket: Bucket{GroupStats: GroupStats{Key: "Tue"}},
+			want: map[string]any{
+				"key": "Tue", "totalSessions": 0.0, "focusSessions": 0.0,
+				"totalTime": 0.0, "focusTime": 0.0, "interruptions": 0.0,
+				"focusSeconds": 0.0, "totalSeconds": 0.0,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.bucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got map[string]any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Errorf("got fields %s, want %v", data, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("%s: got %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+}
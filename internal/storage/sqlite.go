@@ -3,9 +3,11 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,17 +16,6 @@ import (
 	"github.com/zjom/pom/internal/pomodoro"
 )
 
-const schema = `
-CREATE TABLE IF NOT EXISTS sessions (
-	id               INTEGER PRIMARY KEY AUTOINCREMENT,
-	name             TEXT,
-	session_type     TEXT NOT NULL,
-	duration_seconds INTEGER NOT NULL,
-	started_at       DATETIME NOT NULL,
-	completed_at     DATETIME NOT NULL
-);
-`
-
 type SQLiteStore struct {
 	db *sql.DB
 }
@@ -43,13 +34,13 @@ func DefaultDBPath() (string, error) {
 }
 
 func NewSQLiteStore(dbPath string) (*SQLiteStore, error) {
-	db, err := sql.Open("sqlite", dbPath)
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
-	if _, err := db.Exec(schema); err != nil {
+	if err := migrate(context.Background(), db, dbPath); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("create schema: %w", err)
+		return nil, err
 	}
 	return &SQLiteStore{db: db}, nil
 }
@@ -59,51 +50,251 @@ func (s *SQLiteStore) Close() error {
 }
 
 func (s *SQLiteStore) SaveSession(ctx context.Context, sr pomodoro.SessionResult) error {
-	_, err := s.db.ExecContext(ctx,
-		`INSERT INTO sessions (name, session_type, duration_seconds, started_at, completed_at)
-		 VALUES (?, ?, ?, ?, ?)`,
-		sr.Name, string(sr.SessionType), sr.Duration, sr.StartedAt, sr.CompletedAt,
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := saveSession(ctx, tx, sr); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ImportSessions saves sessions in a single transaction, so either all of
+// them are saved or, on error, none are. Projects they name that don't exist
+// yet are created, and their names returned.
+func (s *SQLiteStore) ImportSessions(ctx context.Context, sessions []pomodoro.SessionResult) ([]string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var created []string
+	for i, sr := range sessions {
+		if sr.Project != "" && !slices.Contains(created, sr.Project) {
+			res, err := tx.ExecContext(ctx,
+				`INSERT INTO projects (name, created_at) VALUES (?, ?) ON CONFLICT (name) DO NOTHING`,
+				sr.Project, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("create project %q: %w", sr.Project, err)
+			}
+			if n, err := res.RowsAffected(); err == nil && n > 0 {
+				created = append(created, sr.Project)
+			}
+		}
+		if err := saveSession(ctx, tx, sr); err != nil {
+			return nil, fmt.Errorf("session %d, starting %s: %w", i+1, sr.StartedAt.Format(time.RFC3339), err)
+		}
+	}
+	return created, tx.Commit()
+}
+
+// saveSession inserts sr with its pauses, interruptions and tags.
+func saveSession(ctx context.Context, tx *sql.Tx, sr pomodoro.SessionResult) error {
+	var projectID sql.NullInt64
+	if sr.Project != "" {
+		err := tx.QueryRowContext(ctx, `SELECT id FROM projects WHERE name = ?`, sr.Project).Scan(&projectID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %q", ErrProjectNotFound, sr.Project)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`INSERT INTO sessions (name, profile, project_id, note, session_type, status, duration_seconds, planned_seconds,
+			extended_seconds, paused_seconds, idle_seconds, started_at, completed_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		sr.Name, sr.Profile, projectID, sr.Note, string(sr.SessionType), string(sr.Status), sr.Duration, sr.PlannedDuration,
+		sr.ExtendedBy, sr.PausedDuration, sr.IdleBefore, sr.StartedAt, sr.CompletedAt,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	for _, p := range sr.Pauses {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO pauses (session_id, started_at, ended_at) VALUES (?, ?, ?)`,
+			id, p.StartedAt, p.EndedAt,
+		); err != nil {
+			return err
+		}
+	}
+	for _, in := range sr.Interruptions {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO interruptions (session_id, kind, note, occurred_at) VALUES (?, ?, ?, ?)`,
+			id, string(in.Kind), in.Note, in.At,
+		); err != nil {
+			return err
+		}
+	}
+	return saveTags(ctx, tx, id, sr.Tags)
+}
+
+// saveTags links a session to the named tags, creating any that are new.
+func saveTags(ctx context.Context, tx *sql.Tx, sessionID int64, tags []string) error {
+	for _, name := range tags {
+		if _, err := tx.ExecContext(ctx,
+			`INSERT INTO tags (name) VALUES (?) ON CONFLICT (name) DO NOTHING`, name,
+		); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx,
+			`INSERT OR IGNORE INTO session_tags (session_id, tag_id)
+			 SELECT ?, id FROM tags WHERE name = ?`, sessionID, name,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (s *SQLiteStore) ListSessions(ctx context.Context, f QueryFilter) ([]pomodoro.SessionResult, error) {
-	query := `SELECT name, session_type, duration_seconds, started_at, completed_at FROM sessions`
 	where, args := buildWhere(f)
 	if where != "" {
-		query += " WHERE " + where
+		where = " WHERE " + where
 	}
-	query += " ORDER BY started_at DESC"
+	order := " ORDER BY started_at DESC"
 	if f.Limit > 0 {
-		query += fmt.Sprintf(" LIMIT %d", f.Limit)
+		order += fmt.Sprintf(" LIMIT %d", f.Limit)
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, COALESCE(profile, ''),
+		COALESCE((SELECT projects.name FROM projects WHERE projects.id = sessions.project_id), ''),
+		note, session_type, status, duration_seconds,
+		COALESCE(planned_seconds, duration_seconds), extended_seconds, paused_seconds, idle_seconds,
+		started_at, completed_at FROM sessions`+where+order, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
 	var results []pomodoro.SessionResult
+	index := make(map[int64]int)
 	for rows.Next() {
 		var r pomodoro.SessionResult
-		var st string
-		if err := rows.Scan(&r.Name, &st, &r.Duration, &r.StartedAt, &r.CompletedAt); err != nil {
+		var id int64
+		var st, status string
+		if err := rows.Scan(&id, &r.Name, &r.Profile, &r.Project, &r.Note, &st, &status, &r.Duration, &r.PlannedDuration,
+			&r.ExtendedBy, &r.PausedDuration, &r.IdleBefore, &r.StartedAt, &r.CompletedAt); err != nil {
 			return nil, err
 		}
+		r.ID = id
 		r.SessionType = pomodoro.SessionType(st)
+		r.Status = pomodoro.SessionStatus(status)
+		index[id] = len(results)
 		results = append(results, r)
 	}
-	return results, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// Child rows are fetched for the same selection of sessions in one query
+	// per table rather than one per session.
+	ids := `SELECT id FROM sessions` + where + order
+	if err := s.loadPauses(ctx, ids, args, index, results); err != nil {
+		return nil, fmt.Errorf("load pauses: %w", err)
+	}
+	if err := s.loadInterruptions(ctx, ids, args, index, results); err != nil {
+		return nil, fmt.Errorf("load interruptions: %w", err)
+	}
+	if err := s.loadTags(ctx, ids, args, index, results); err != nil {
+		return nil, fmt.Errorf("load tags: %w", err)
+	}
+	return results, nil
+}
+
+func (s *SQLiteStore) loadPauses(ctx context.Context, ids string, args []any, index map[int64]int, results []pomodoro.SessionResult) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT session_id, started_at, ended_at FROM pauses
+		 WHERE session_id IN (`+ids+`) ORDER BY started_at`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var p pomodoro.Pause
+		if err := rows.Scan(&id, &p.StartedAt, &p.EndedAt); err != nil {
+			return err
+		}
+		r := &results[index[id]]
+		r.Pauses = append(r.Pauses, p)
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) loadInterruptions(ctx context.Context, ids string, args []any, index map[int64]int, results []pomodoro.SessionResult) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT session_id, kind, note, occurred_at FROM interruptions
+		 WHERE session_id IN (`+ids+`) ORDER BY occurred_at`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var kind string
+		var in pomodoro.Interruption
+		if err := rows.Scan(&id, &kind, &in.Note, &in.At); err != nil {
+			return err
+		}
+		in.Kind = pomodoro.InterruptionKind(kind)
+		r := &results[index[id]]
+		r.Interruptions = append(r.Interruptions, in)
+	}
+	return rows.Err()
+}
+
+func (s *SQLiteStore) loadTags(ctx context.Context, ids string, args []any, index map[int64]int, results []pomodoro.SessionResult) error {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT st.session_id, t.name FROM session_tags st JOIN tags t ON t.id = st.tag_id
+		 WHERE st.session_id IN (`+ids+`) ORDER BY t.name`, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var name string
+		if err := rows.Scan(&id, &name); err != nil {
+			return err
+		}
+		r := &results[index[id]]
+		r.Tags = append(r.Tags, name)
+	}
+	return rows.Err()
 }
 
 func (s *SQLiteStore) GetStatistics(ctx context.Context, f QueryFilter) (*Statistics, error) {
-	query := `SELECT session_type, COUNT(*), COALESCE(SUM(duration_seconds), 0) FROM sessions`
+	query := `SELECT session_type, status, COUNT(*), COALESCE(SUM(duration_seconds), 0),
+		COALESCE(SUM(COALESCE(planned_seconds, duration_seconds)), 0),
+		COALESCE(SUM(paused_seconds), 0),
+		COALESCE(SUM(idle_seconds), 0),
+		COALESCE(SUM((SELECT COUNT(*) FROM pauses WHERE pauses.session_id = sessions.id)), 0),
+		COALESCE(SUM((SELECT COUNT(*) FROM interruptions i WHERE i.session_id = sessions.id AND i.kind = 'internal')), 0),
+		COALESCE(SUM((SELECT COUNT(*) FROM interruptions i WHERE i.session_id = sessions.id AND i.kind = 'external')), 0)
+		FROM sessions`
 	where, args := buildWhere(f)
 	if where != "" {
 		query += " WHERE " + where
 	}
-	query += " GROUP BY session_type"
+	query += " GROUP BY session_type, status"
 
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
@@ -111,50 +302,198 @@ func (s *SQLiteStore) GetStatistics(ctx context.Context, f QueryFilter) (*Statis
 	}
 	defer rows.Close()
 
-	stats := &Statistics{ByType: make(map[string]int)}
-	var totalSeconds int64
+	stats := &Statistics{
+		ByType:   make(map[string]int),
+		ByStatus: make(map[string]int),
+	}
+	var totalSeconds, focusSeconds, plannedFocusSeconds, pausedSeconds, idleSeconds, focusWallSeconds, flowSeconds int64
+	var focusSessions, completedFocus, focusInterruptions int
 	for rows.Next() {
-		var st string
-		var count int
-		var seconds int64
-		if err := rows.Scan(&st, &count, &seconds); err != nil {
+		var st, status string
+		var count, pauses, internal, external int
+		var seconds, planned, paused, idle int64
+		if err := rows.Scan(&st, &status, &count, &seconds, &planned, &paused, &idle, &pauses, &internal, &external); err != nil {
 			return nil, err
 		}
-		stats.ByType[st] = count
+		stats.InternalInterruptions += internal
+		stats.ExternalInterruptions += external
+		stats.ByType[st] += count
+		stats.ByStatus[status] += count
 		stats.TotalSessions += count
+		stats.Pauses += pauses
 		totalSeconds += seconds
+		pausedSeconds += paused
+		idleSeconds += idle
+		if pomodoro.SessionType(st) == pomodoro.Flow {
+			flowSeconds += seconds
+			focusInterruptions += internal + external
+		}
+		if pomodoro.SessionType(st) == pomodoro.Focus {
+			focusSessions += count
+			focusSeconds += seconds
+			focusWallSeconds += seconds + paused
+			plannedFocusSeconds += planned
+			focusInterruptions += internal + external
+			if pomodoro.SessionStatus(status) == pomodoro.Completed {
+				completedFocus += count
+			}
+		}
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
 
 	stats.TotalTime = time.Duration(totalSeconds) * time.Second
+	stats.FocusTime = time.Duration(focusSeconds) * time.Second
+	stats.FocusWallTime = time.Duration(focusWallSeconds) * time.Second
+	stats.FlowTime = time.Duration(flowSeconds) * time.Second
+	stats.PlannedFocusTime = time.Duration(plannedFocusSeconds) * time.Second
+	stats.PausedTime = time.Duration(pausedSeconds) * time.Second
+	stats.IdleTime = time.Duration(idleSeconds) * time.Second
 	if stats.TotalSessions > 0 {
 		stats.AverageDuration = stats.TotalTime / time.Duration(stats.TotalSessions)
 	}
+	// Interruptions logged during breaks don't break focus, so only those
+	// in focus and flow sessions are counted, against their net time.
+	if worked := focusSeconds + flowSeconds; worked > 0 {
+		stats.InterruptionsPerFocusHour = float64(focusInterruptions) / (time.Duration(worked) * time.Second).Hours()
+	}
+	if focusSessions > 0 {
+		stats.CompletionRate = float64(completedFocus) / float64(focusSessions)
+	}
 	return stats, nil
 }
 
+// groupSources maps each GroupKey to the expression it groups by and any
+// join needed to reach it. A session with several tags counts once per tag.
+var groupSources = map[GroupKey]struct{ expr, join string }{
+	GroupByType:    {expr: "sessions.session_type"},
+	GroupByProfile: {expr: "COALESCE(sessions.profile, '')"},
+	GroupByProject: {
+		expr: "COALESCE(projects.name, '')",
+		join: " LEFT JOIN projects ON projects.id = sessions.project_id",
+	},
+	GroupByTag: {
+		expr: "COALESCE(tags.name, '')",
+		join: " LEFT JOIN session_tags ON session_tags.session_id = sessions.id LEFT JOIN tags ON tags.id = session_tags.tag_id",
+	},
+}
+
+func (s *SQLiteStore) GetBreakdown(ctx context.Context, f QueryFilter, by GroupKey) ([]GroupStats, error) {
+	src, ok := groupSources[by]
+	if !ok {
+		return nil, fmt.Errorf("unsupported grouping %q", by)
+	}
+
+	query := fmt.Sprintf(`SELECT %s, COUNT(*),
+		COALESCE(SUM(sessions.session_type IN (?, ?)), 0),
+		COALESCE(SUM(sessions.duration_seconds), 0),
+		COALESCE(SUM(CASE WHEN sessions.session_type IN (?, ?) THEN sessions.duration_seconds ELSE 0 END), 0)
+		FROM sessions%s`, src.expr, src.join)
+	focus, flow := string(pomodoro.Focus), string(pomodoro.Flow)
+	args := []any{focus, flow, focus, flow}
+	where, whereArgs := buildWhere(f)
+	if where != "" {
+		query += " WHERE " + where
+		args = append(args, whereArgs...)
+	}
+	query += " GROUP BY 1 ORDER BY 1"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []GroupStats
+	for rows.Next() {
+		var g GroupStats
+		var totalSeconds, focusSeconds int64
+		if err := rows.Scan(&g.Key, &g.TotalSessions, &g.FocusSessions, &totalSeconds, &focusSeconds); err != nil {
+			return nil, err
+		}
+		g.TotalTime = time.Duration(totalSeconds) * time.Second
+		g.FocusTime = time.Duration(focusSeconds) * time.Second
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// buildWhere translates f into a WHERE clause over the sessions table.
+// Columns are qualified so the clause stays unambiguous when other tables are
+// joined in.
 func buildWhere(f QueryFilter) (string, []any) {
-	var clauses []string
+	clauses := []string{"sessions.deleted_at IS NULL"}
 	var args []any
 
+	if f.ID != 0 {
+		clauses = append(clauses, "sessions.id = ?")
+		args = append(args, f.ID)
+	}
 	if f.Name != "" {
-		clauses = append(clauses, "name = ?")
+		clauses = append(clauses, "sessions.name = ?")
 		args = append(args, f.Name)
 	}
+	if f.Profile != "" {
+		clauses = append(clauses, "sessions.profile = ?")
+		args = append(args, f.Profile)
+	}
+	if f.Project != "" {
+		clauses = append(clauses, "sessions.project_id = (SELECT id FROM projects WHERE projects.name = ?)")
+		args = append(args, f.Project)
+	}
+	if f.Search != "" {
+		clauses = append(clauses, "sessions.id IN (SELECT rowid FROM sessions_fts WHERE sessions_fts MATCH ?)")
+		args = append(args, ftsQuery(f.Search))
+	}
 	if f.SessionType != nil {
-		clauses = append(clauses, "session_type = ?")
+		clauses = append(clauses, "sessions.session_type = ?")
 		args = append(args, string(*f.SessionType))
 	}
+	if f.Status != nil {
+		clauses = append(clauses, "sessions.status = ?")
+		args = append(args, string(*f.Status))
+	}
 	if f.From != nil {
-		clauses = append(clauses, "started_at >= ?")
+		clauses = append(clauses, "sessions.started_at >= ?")
 		args = append(args, *f.From)
 	}
 	if f.To != nil {
-		clauses = append(clauses, "completed_at <= ?")
+		clauses = append(clauses, "sessions.completed_at <= ?")
 		args = append(args, *f.To)
 	}
+	if len(f.Tags) > 0 {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(f.Tags)), ", ")
+		clause := `sessions.id IN (SELECT st.session_id FROM session_tags st
+			JOIN tags t ON t.id = st.tag_id WHERE t.name IN (` + placeholders + `)`
+		if f.MatchAllTags {
+			clause += fmt.Sprintf(" GROUP BY st.session_id HAVING COUNT(DISTINCT t.name) = %d", len(f.Tags))
+		}
+		clauses = append(clauses, clause+")")
+		for _, t := range f.Tags {
+			args = append(args, t)
+		}
+	}
 
 	return strings.Join(clauses, " AND "), args
 }
+
+// ftsQuery turns free text into an FTS5 query matching every word in it. Each
+// word is quoted so punctuation is taken literally; a trailing * still makes
+// it a prefix search.
+func ftsQuery(text string) string {
+	var terms []string
+	for _, w := range strings.Fields(text) {
+		prefix := strings.HasSuffix(w, "*")
+		w = strings.TrimRight(w, "*")
+		if w == "" {
+			continue
+		}
+		term := `"` + strings.ReplaceAll(w, `"`, `""`) + `"`
+		if prefix {
+			term += "*"
+		}
+		terms = append(terms, term)
+	}
+	return strings.Join(terms, " ")
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/zjom/pom/internal/pomodoro"
 )
@@ -9,7 +10,28 @@ import (
 // Store defines the interface for session persistence.
 type Store interface {
 	SaveSession(ctx context.Context, s pomodoro.SessionResult) error
+	ImportSessions(ctx context.Context, sessions []pomodoro.SessionResult) ([]string, error)
 	ListSessions(ctx context.Context, f QueryFilter) ([]pomodoro.SessionResult, error)
+	EachSession(ctx context.Context, f QueryFilter, fn func(pomodoro.SessionResult) error) error
 	GetStatistics(ctx context.Context, f QueryFilter) (*Statistics, error)
+	GetBreakdown(ctx context.Context, f QueryFilter, by GroupKey) ([]GroupStats, error)
+	GetTimeSeries(ctx context.Context, f QueryFilter, period Period, loc *time.Location) ([]Bucket, error)
+
+	GetSession(ctx context.Context, id int64) (*pomodoro.SessionResult, error)
+	UpdateSession(ctx context.Context, s pomodoro.SessionResult) error
+	DeleteSession(ctx context.Context, id int64) error
+	Undo(ctx context.Context) (*UndoEntry, error)
+	Revisions(ctx context.Context) (map[int64]Revision, error)
+	DatabaseID(ctx context.Context) (string, error)
+
+	CreateProject(ctx context.Context, p Project) error
+	GetProject(ctx context.Context, name string) (*Project, error)
+	ListProjects(ctx context.Context, includeArchived bool) ([]Project, error)
+	ArchiveProject(ctx context.Context, name string, at time.Time) error
+
+	SaveCheckpoint(ctx context.Context, cp pomodoro.Checkpoint) error
+	LoadCheckpoint(ctx context.Context) (*pomodoro.Checkpoint, error)
+	ClearCheckpoint(ctx context.Context) error
+
 	Close() error
 }
@@ -0,0 +1,139 @@
+// Package streak tracks progress towards the daily focus goal and the run of
+// consecutive days on which it was met.
+package streak
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+// Summary is today's progress towards the daily goal and the streaks leading
+// up to it. Today's counts can be bumped as sessions finish; Current and
+// Longest take them into account.
+type Summary struct {
+	Goal config.DailyGoal `json:"-"`
+
+	Sessions int           `json:"sessions"`  // completed focus sessions today
+	Focus    time.Duration `json:"focusTime"` // net focus time today
+
+	day           time.Time // local midnight at the start of today
+	before        int       // streak ending yesterday
+	longestBefore int       // longest streak ending before today
+}
+
+// Load computes the summary for the day containing now from the sessions in
+// store. Days are local calendar days.
+func Load(ctx context.Context, store storage.Store, goal config.DailyGoal, now time.Time) (Summary, error) {
+	now = now.Local()
+	y, m, dd := now.Date()
+	today := time.Date(y, m, dd, 0, 0, 0, 0, time.Local)
+	s := Summary{Goal: goal, day: today}
+	if !goal.IsSet() {
+		return s, nil
+	}
+
+	// Session goals count completed sessions; time goals count every minute
+	// of focus, including sessions that were cut short.
+	var f storage.QueryFilter
+	if goal.Sessions > 0 {
+		completed := pomodoro.Completed
+		f.Status = &completed
+	}
+	days, err := store.GetTimeSeries(ctx, f, storage.PeriodDay, time.Local)
+	if err != nil {
+		return s, fmt.Errorf("query daily totals: %w", err)
+	}
+
+	met := make(map[string]bool, len(days))
+	for _, d := range days {
+		met[d.Key] = s.reached(d.FocusSessions, d.FocusTime)
+	}
+
+	if len(days) > 0 && days[len(days)-1].Key == today.Format("2006-01-02") {
+		s.Sessions = days[len(days)-1].FocusSessions
+		s.Focus = days[len(days)-1].FocusTime
+	}
+	if len(days) == 0 {
+		return s, nil
+	}
+
+	// Walk forward from the first recorded day to yesterday.
+	run := 0
+	for day := days[0].Start; day.Before(today); day = day.AddDate(0, 0, 1) {
+		switch {
+		case s.dayOff(day):
+		case met[day.Format("2006-01-02")]:
+			run++
+			s.longestBefore = max(s.longestBefore, run)
+		default:
+			run = 0
+		}
+	}
+	s.before = run
+	return s, nil
+}
+
+// Covers reports whether t falls on the day s was loaded for, so its
+// figures are still today's.
+func (s Summary) Covers(t time.Time) bool {
+	y, m, d := t.Local().Date()
+	return s.day.Equal(time.Date(y, m, d, 0, 0, 0, 0, time.Local))
+}
+
+// Met reports whether today's goal has been reached.
+func (s Summary) Met() bool {
+	return s.Goal.IsSet() && s.reached(s.Sessions, s.Focus)
+}
+
+func (s Summary) reached(sessions int, focus time.Duration) bool {
+	if s.Goal.Sessions > 0 {
+		return sessions >= s.Goal.Sessions
+	}
+	return focus >= s.Goal.Focus
+}
+
+// Current is the number of consecutive days the goal has been met, counting
+// today once it is. A day still in progress never breaks the streak, and
+// days off neither break nor extend it.
+func (s Summary) Current() int {
+	if s.Met() && !s.dayOff(s.day) {
+		return s.before + 1
+	}
+	return s.before
+}
+
+// Longest is the longest streak on record, including the current one.
+func (s Summary) Longest() int {
+	return max(s.longestBefore, s.Current())
+}
+
+// Percent is today's progress towards the goal, capped at 100.
+func (s Summary) Percent() float64 {
+	var p float64
+	switch {
+	case s.Goal.Sessions > 0:
+		p = 100 * float64(s.Sessions) / float64(s.Goal.Sessions)
+	case s.Goal.Focus > 0:
+		p = 100 * s.Focus.Hours() / s.Goal.Focus.Hours()
+	}
+	return min(p, 100)
+}
+
+// Progress describes today's progress, e.g. "3/8 sessions" or
+// "1h 40m 0s / 3h 20m 0s".
+func (s Summary) Progress() string {
+	if s.Goal.Sessions > 0 {
+		return fmt.Sprintf("%d/%d sessions", s.Sessions, s.Goal.Sessions)
+	}
+	return fmt.Sprintf("%s / %s", pomodoro.FormatDuration(s.Focus), pomodoro.FormatDuration(s.Goal.Focus))
+}
+
+// dayOff reports whether day is a weekend and the goal has weekends off.
+func (s Summary) dayOff(day time.Time) bool {
+	return s.Goal.WeekendsOff && (day.Weekday() == time.Saturday || day.Weekday() == time.Sunday)
+}
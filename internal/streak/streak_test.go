@@ -0,0 +1,157 @@
+package streak
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/pomodoro"
+	"github.com/zjom/pom/internal/storage"
+)
+
+// A day's focus sessions, 25 minutes each.
+const (
+	met     = "met"     // two completed
+	partial = "partial" // one completed
+	mixed   = "mixed"   // one completed and one aborted
+)
+
+// history is the synthetic series every case loads, from Monday 28
+// September to Friday 9 October 2026. Days not listed have no sessions.
+var history = map[string]string{
+	"2026-09-28": met, // Mon
+	"2026-09-29": met,
+	"2026-09-30": partial,
+	"2026-10-01": met,
+	"2026-10-02": met,
+	"2026-10-03": met, // Sat; Sun 4th is empty
+	"2026-10-05": met, // Mon
+	"2026-10-06": mixed,
+	"2026-10-07": met,
+	"2026-10-08": met,
+	"2026-10-09": met, // Fri
+}
+
+func TestLoad(t *testing.T) {
+	sessions := config.DailyGoal{Sessions: 2}
+	minutes := config.DailyGoal{Focus: 50 * time.Minute}
+	weekendsOff := func(g config.DailyGoal) config.DailyGoal {
+		g.WeekendsOff = true
+		return g
+	}
+
+	tests := []struct {
+		name  string
+		goal  config.DailyGoal
+		today string // the day Load is called on
+		done  string // today's sessions so far, "" for none
+		// want
+		met              bool
+		current, longest int
+		progress         string
+	}{
+		{
+			name: "sessions, unfinished today keeps nothing", goal: sessions, today: "2026-10-07",
+			current: 0, longest: 3, progress: "0/2 sessions",
+		},
+		{
+			name: "sessions, today met", goal: sessions, today: "2026-10-07", done: met,
+			met: true, current: 1, longest: 3, progress: "2/2 sessions",
+		},
+		{
+			name: "sessions, aborted sessions don't count", goal: sessions, today: "2026-10-07", done: mixed,
+			current: 0, longest: 3, progress: "1/2 sessions",
+		},
+		{
+			name: "sessions, weekend met extends without weekends off", goal: sessions, today: "2026-10-10", done: met,
+			met: true, current: 4, longest: 4, progress: "2/2 sessions",
+		},
+		{
+			// A met Saturday doesn't extend the run of the 1st and 2nd,
+			// and the empty Sunday doesn't break it.
+			name: "sessions, weekends off", goal: weekendsOff(sessions), today: "2026-10-07", done: met,
+			met: true, current: 1, longest: 3, progress: "2/2 sessions",
+		},
+		{
+			name: "sessions, weekends off, met saturday today", goal: weekendsOff(sessions), today: "2026-10-10", done: met,
+			met: true, current: 3, longest: 3, progress: "2/2 sessions",
+		},
+		{
+			name: "minutes, unfinished today keeps the streak", goal: minutes, today: "2026-10-07", done: partial,
+			current: 2, longest: 3, progress: "25m 0s / 50m 0s",
+		},
+		{
+			name: "minutes, aborted sessions count", goal: minutes, today: "2026-10-07", done: mixed,
+			met: true, current: 3, longest: 3, progress: "50m 0s / 50m 0s",
+		},
+		{
+			name: "minutes, weekends off", goal: weekendsOff(minutes), today: "2026-10-07", done: met,
+			met: true, current: 5, longest: 5, progress: "50m 0s / 50m 0s",
+		},
+		{
+			name: "minutes, weekends off, empty saturday today", goal: weekendsOff(minutes), today: "2026-10-10",
+			current: 7, longest: 7, progress: "0s / 50m 0s",
+		},
+		{
+			name: "minutes, weekends off, met saturday today", goal: weekendsOff(minutes), today: "2026-10-10", done: met,
+			met: true, current: 7, longest: 7, progress: "50m 0s / 50m 0s",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			store, err := storage.NewSQLiteStore(filepath.Join(t.TempDir(), "history.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+
+			save := func(day, kind string) {
+				start, err := time.ParseInLocation("2006-01-02 15:04", day+" 09:00", time.Local)
+				if err != nil {
+					t.Fatal(err)
+				}
+				statuses := map[string][]pomodoro.SessionStatus{
+					met:     {pomodoro.Completed, pomodoro.Completed},
+					partial: {pomodoro.Completed},
+					mixed:   {pomodoro.Completed, pomodoro.Aborted},
+				}[kind]
+				for i, status := range statuses {
+					from := start.Add(time.Duration(i) * time.Hour)
+					err := store.SaveSession(ctx, pomodoro.SessionResult{
+						SessionType:     pomodoro.Focus,
+						Status:          status,
+						Duration:        25 * 60,
+						PlannedDuration: 25 * 60,
+						StartedAt:       from,
+						CompletedAt:     from.Add(25 * time.Minute),
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+			for day, kind := range history {
+				if day < tt.today {
+					save(day, kind)
+				}
+			}
+			save(tt.today, tt.done)
+
+			now, err := time.ParseInLocation("2006-01-02 15:04", tt.today+" 18:00", time.Local)
+			if err != nil {
+				t.Fatal(err)
+			}
+			s, err := Load(ctx, store, tt.goal, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Met() != tt.met || s.Current() != tt.current || s.Longest() != tt.longest || s.Progress() != tt.progress {
+				t.Errorf("got met %t, current %d, longest %d, %q; want met %t, current %d, longest %d, %q",
+					s.Met(), s.Current(), s.Longest(), s.Progress(), tt.met, tt.current, tt.longest, tt.progress)
+			}
+		})
+	}
+}
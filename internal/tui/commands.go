@@ -1,26 +1,58 @@
 package tui
 
 import (
-	"log"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/gen2brain/beeep"
+
+	"github.com/zjom/pom/internal/daemon"
+	"github.com/zjom/pom/internal/streak"
 )
 
-type tickMsg time.Time
+// updateMsg is an update from the timer. err is set once the timer has
+// stopped sending, because it stopped or the connection was lost.
+type updateMsg struct {
+	update daemon.Update
+	err    error
+}
+
+// waitUpdate delivers the timer's next update.
+func waitUpdate(sub *daemon.Subscription) tea.Cmd {
+	return func() tea.Msg {
+		u, err := sub.Next()
+		return updateMsg{update: u, err: err}
+	}
+}
+
+// replyMsg is the timer's reply to a command.
+type replyMsg struct {
+	state *daemon.State
+	err   error
+}
 
-func tickCmd() tea.Cmd {
-	return tea.Tick(time.Second, func(t time.Time) tea.Msg {
-		return tickMsg(t)
-	})
+// sendCmd sends req to the timer and delivers its reply.
+func sendCmd(req daemon.Request) tea.Cmd {
+	return func() tea.Msg {
+		s, err := daemon.Send(req)
+		return replyMsg{state: s, err: err}
+	}
 }
 
-func notifyCmd(title, message string) tea.Cmd {
+// streakMsg carries the daily goal progress reloaded for a new day.
+type streakMsg struct {
+	summary streak.Summary
+	err     error
+}
+
+// loadStreakCmd reloads the daily goal progress for the day containing now.
+func loadStreakCmd(load func(time.Time) (streak.Summary, error), now time.Time) tea.Cmd {
 	return func() tea.Msg {
-		if err := beeep.Notify(title, message, ""); err != nil {
-			log.Printf("Failed to send notification: %v", err)
-		}
-		return nil
+		s, err := load(now)
+		return streakMsg{summary: s, err: err}
 	}
 }
+
+// callCmd sends a command that takes no arguments.
+func callCmd(cmd string) tea.Cmd {
+	return sendCmd(daemon.Request{Cmd: cmd})
+}
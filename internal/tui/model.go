@@ -8,34 +8,57 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/zjom/pom/internal/config"
+	"github.com/zjom/pom/internal/daemon"
 	"github.com/zjom/pom/internal/pomodoro"
 	"github.com/zjom/pom/internal/storage"
+	"github.com/zjom/pom/internal/streak"
 )
 
-// Model holds all TUI state for the pomodoro timer.
+// Model holds all TUI state for the pomodoro timer. The timer itself runs
+// in the daemon; the model is attached to it, shows the State it sends and
+// drives it over the socket. Quitting with q stops the timer, detaching
+// leaves it running.
 type Model struct {
-	Cfg           config.Config
-	Store         storage.Store
-	CurrentType   pomodoro.SessionType
-	TargetTime    time.Time
-	StartTime     time.Time
-	SessionStart  time.Time // start of the current individual session/break
-	TotalDuration time.Duration
-	SessionsDone  int
-	Quitting      bool
+	Cfg      config.Config
+	State    daemon.State
+	Err      error // the last command the timer refused
+	Stopped  bool  // the timer has stopped, from here or elsewhere
+	Quitting bool
 
+	sub    *daemon.Subscription
 	width  int
 	height int
 
-	IsPaused   bool
-	IsRenaming bool
-	ShowHelp   bool
-	TimeLeft   time.Duration
-	TextInput  textinput.Model
-	Progress   progress.Model
+	IsRenaming   bool
+	renamePaused bool // the countdown was paused for renaming and resumes after
+	ShowHelp     bool
+
+	// Project is the project sessions are logged against, if any. WeekFocus
+	// is the focus time logged to it since the start of the week, including
+	// sessions finished while attached.
+	Project   *storage.Project
+	WeekFocus time.Duration
+
+	// Streak is progress towards the daily goal, kept up to date as
+	// sessions finish. LoadStreak reloads it for the day containing now,
+	// which happens when the timer's clock passes midnight.
+	Streak        streak.Summary
+	LoadStreak    func(now time.Time) (streak.Summary, error)
+	streakLoading bool
+
+	// Noting is set while prompting for a note on the focus session that
+	// ended at EndedAt, when it had run for NotedElapsed.
+	Noting       bool
+	EndedAt      time.Time
+	NotedElapsed time.Duration
+
+	Interrupting pomodoro.InterruptionKind // set while prompting for an interruption note
+	TextInput    textinput.Model
+	Progress     progress.Model
 }
 
-func NewModel(cfg config.Config, store storage.Store) Model {
+// NewModel shows the timer sub is attached to, starting from state s.
+func NewModel(cfg config.Config, sub *daemon.Subscription, s daemon.State) Model {
 	ti := textinput.New()
 	ti.Placeholder = "Enter new session name"
 	ti.CharLimit = 50
@@ -44,20 +67,15 @@ func NewModel(cfg config.Config, store storage.Store) Model {
 	prog := progress.New(progress.WithDefaultGradient())
 	prog.Width = 40
 
-	now := time.Now()
 	return Model{
-		Cfg:           cfg,
-		Store:         store,
-		CurrentType:   pomodoro.Focus,
-		StartTime:     now,
-		SessionStart:  now,
-		TargetTime:    now.Add(cfg.SessionDuration),
-		TotalDuration: cfg.SessionDuration,
-		TextInput:     ti,
-		Progress:      prog,
+		Cfg:       cfg,
+		State:     s,
+		sub:       sub,
+		TextInput: ti,
+		Progress:  prog,
 	}
 }
 
 func (m Model) Init() tea.Cmd {
-	return tea.Batch(tickCmd(), textinput.Blink)
+	return tea.Batch(waitUpdate(m.sub), textinput.Blink)
 }
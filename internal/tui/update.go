@@ -1,13 +1,13 @@
 package tui
 
 import (
-	"context"
-	"log"
+	"errors"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/zjom/pom/internal/daemon"
 	"github.com/zjom/pom/internal/pomodoro"
 )
 
@@ -29,91 +29,238 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.IsRenaming {
 			switch msg.Type {
 			case tea.KeyEnter:
-				if m.TextInput.Value() != "" {
-					m.Cfg.SessionName = m.TextInput.Value()
+				var rename tea.Cmd
+				if name := m.TextInput.Value(); name != "" {
+					rename = sendCmd(daemon.Request{Cmd: daemon.CmdRename, Text: name})
 				}
-				m.IsRenaming = false
-				m.TextInput.Blur()
-				m.TargetTime = time.Now().Add(m.TimeLeft)
-				return m, nil
+				m, cmd = m.endRename()
+				return m, tea.Sequence(rename, cmd)
 			case tea.KeyEsc:
-				m.IsRenaming = false
+				return m.endRename()
+			}
+			m.TextInput, cmd = m.TextInput.Update(msg)
+			return m, cmd
+		}
+
+		if m.Interrupting != "" {
+			switch msg.Type {
+			case tea.KeyEnter, tea.KeyEsc:
+				req := daemon.Request{Cmd: daemon.CmdInterrupt, Kind: m.Interrupting}
+				if msg.Type == tea.KeyEnter {
+					req.Text = m.TextInput.Value()
+				}
+				m.Interrupting = ""
 				m.TextInput.Blur()
-				m.TargetTime = time.Now().Add(m.TimeLeft)
-				return m, nil
+				return m, sendCmd(req)
 			}
 			m.TextInput, cmd = m.TextInput.Update(msg)
 			return m, cmd
 		}
 
+		if m.Noting {
+			switch msg.Type {
+			case tea.KeyEnter, tea.KeyEsc:
+				req := daemon.Request{Cmd: daemon.CmdComplete, At: m.EndedAt}
+				if msg.Type == tea.KeyEnter {
+					req.Text = m.TextInput.Value()
+				}
+				m.Noting = false
+				m.TextInput.Blur()
+				return m, sendCmd(req)
+			}
+			m.TextInput, cmd = m.TextInput.Update(msg)
+			return m, cmd
+		}
+
+		if m.State.Waiting {
+			switch msg.String() {
+			case "ctrl+c", "q":
+				return m.quit()
+			case "d":
+				return m.detach()
+			case "enter", " ", "p":
+				return m, callCmd(daemon.CmdResume)
+			case "s":
+				// Nothing has run yet, so only the idle gap is kept.
+				return m, callCmd(daemon.CmdSkip)
+			case "?":
+				m.ShowHelp = !m.ShowHelp
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "ctrl+c", "q":
-			m.Quitting = true
-			return m, tea.Quit
+			return m.quit()
+		case "d":
+			return m.detach()
 		case " ", "p":
-			m.IsPaused = !m.IsPaused
-			if m.IsPaused {
-				m.TimeLeft = time.Until(m.TargetTime)
-			} else {
-				m.TargetTime = time.Now().Add(m.TimeLeft)
+			if m.State.Paused {
+				return m, callCmd(daemon.CmdResume)
 			}
+			return m, callCmd(daemon.CmdPause)
 		case "?":
 			m.ShowHelp = !m.ShowHelp
 		case "r":
 			m.IsRenaming = true
-			m.TimeLeft = time.Until(m.TargetTime)
-			m.TextInput.SetValue(m.Cfg.SessionName)
+			m.renamePaused = !m.State.Paused
+			if m.renamePaused {
+				cmd = callCmd(daemon.CmdPause)
+			}
+			m.TextInput.Placeholder = "Enter new session name"
+			m.TextInput.SetValue(m.State.Name)
+			m.TextInput.Focus()
+			return m, tea.Batch(cmd, textinput.Blink)
+		case "'", "-":
+			// The countdown keeps running while the note is typed.
+			m.Interrupting = pomodoro.Internal
+			if msg.String() == "-" {
+				m.Interrupting = pomodoro.External
+			}
+			m.TextInput.Placeholder = "Optional note"
+			m.TextInput.SetValue("")
 			m.TextInput.Focus()
 			return m, textinput.Blink
+		case "s":
+			return m, callCmd(daemon.CmdSkip)
+		case "e":
+			return m, sendCmd(daemon.Request{Cmd: daemon.CmdExtend, Duration: time.Minute})
+		case "E":
+			return m, sendCmd(daemon.Request{Cmd: daemon.CmdExtend, Duration: 5 * time.Minute})
+		case "R":
+			return m, callCmd(daemon.CmdRestart)
+		case "enter":
+			if m.State.SessionType == pomodoro.Flow {
+				return m.nextState()
+			}
 		}
 
-	case tickMsg:
-		if !m.IsPaused && !m.IsRenaming {
-			if time.Now().After(m.TargetTime) {
-				var notify tea.Cmd
-				m, notify = m.nextState()
-				return m, tea.Batch(tickCmd(), notify)
-			}
+	case replyMsg:
+		if errors.Is(msg.err, daemon.ErrNotRunning) {
+			return m.stopped()
+		}
+		m.Err = msg.err
+		if msg.state == nil {
+			return m, nil
+		}
+		return m.follow(*msg.state)
+
+	case updateMsg:
+		if msg.err != nil {
+			return m.stopped()
+		}
+		m = m.count(msg.update.Event)
+		m, cmd = m.follow(msg.update.State)
+		if m.Quitting {
+			return m, cmd
 		}
-		return m, tickCmd()
+		var reload tea.Cmd
+		m, reload = m.newDay(msg.update.State.At)
+		return m, tea.Batch(cmd, reload, waitUpdate(m.sub))
+
+	case streakMsg:
+		if msg.err != nil {
+			// Leave streakLoading set so a failing database isn't queried
+			// again every second.
+			m.Err = msg.err
+			return m, nil
+		}
+		m.Streak = msg.summary
+		m.streakLoading = false
 	}
 
 	return m, nil
 }
 
-func (m Model) nextState() (Model, tea.Cmd) {
-	now := time.Now()
-
-	// Persist the completed session/break.
-	if m.Store != nil {
-		sr := pomodoro.SessionResult{
-			Name:        m.Cfg.SessionName,
-			SessionType: m.CurrentType,
-			Duration:    int(m.TotalDuration.Seconds()),
-			StartedAt:   m.SessionStart,
-			CompletedAt: now,
-		}
-		if err := m.Store.SaveSession(context.Background(), sr); err != nil {
-			log.Printf("Failed to save session: %v", err)
+// count adds the focus time recorded with ev, if any, towards the weekly
+// and daily goals.
+func (m Model) count(ev *pomodoro.Event) Model {
+	if ev == nil {
+		return m
+	}
+	if r := ev.Result; r != nil && (r.SessionType == pomodoro.Focus || r.SessionType == pomodoro.Flow) {
+		m.WeekFocus += r.Net()
+		m.Streak.Focus += r.Net()
+		if r.Status == pomodoro.Completed {
+			m.Streak.Sessions++
 		}
 	}
+	return m
+}
 
-	var cmd tea.Cmd
-	nextType, nextDur, done := pomodoro.NextSession(m.CurrentType, m.SessionsDone, m.Cfg)
-	m.SessionsDone = done
-	m.CurrentType = nextType
-	m.TotalDuration = nextDur
-	m.TargetTime = now.Add(nextDur)
-	m.SessionStart = now
-
-	switch nextType {
-	case pomodoro.ShortBreak:
-		cmd = notifyCmd("Pomodoro", "Focus session complete! Take a quick breather.")
-	case pomodoro.LongBreak:
-		cmd = notifyCmd("Pomodoro", "Focus session complete! Time for a long break.")
-	case pomodoro.Focus:
-		cmd = notifyCmd("Pomodoro", "Break is over. Time to get back to focus!")
+// newDay reloads the daily goal progress once the timer's clock has moved
+// past the day it was loaded for.
+func (m Model) newDay(at time.Time) (Model, tea.Cmd) {
+	if !m.Streak.Goal.IsSet() || m.LoadStreak == nil || m.streakLoading || m.Streak.Covers(at) {
+		return m, nil
+	}
+	m.streakLoading = true
+	return m, loadStreakCmd(m.LoadStreak, at)
+}
+
+// follow shows the timer's latest state. A focus session that runs out
+// while note prompts are on waits in the timer for its note, and a note
+// prompt another client has answered is closed.
+func (m Model) follow(s daemon.State) (Model, tea.Cmd) {
+	if s.Stopped {
+		m.State = s
+		return m.stopped()
+	}
+	if m.Noting && !s.StartedAt.Equal(m.State.StartedAt) {
+		m.Noting = false
+		m.TextInput.Blur()
+	}
+	m.State = s
+
+	if s.Due && s.NotePrompt && !m.Noting && !m.IsRenaming && m.Interrupting == "" {
+		return m.nextState()
 	}
+	return m, nil
+}
+
+// quit stops the timer, recording the current session/break as aborted.
+// The program ends once the timer reports that it has stopped.
+func (m Model) quit() (Model, tea.Cmd) {
+	return m, callCmd(daemon.CmdStop)
+}
+
+// detach ends the program and leaves the timer running.
+func (m Model) detach() (Model, tea.Cmd) {
+	m.Quitting = true
+	return m, tea.Quit
+}
+
+// stopped ends the program after the timer has stopped.
+func (m Model) stopped() (Model, tea.Cmd) {
+	m.Stopped = true
+	m.Quitting = true
+	return m, tea.Quit
+}
 
-	return m, cmd
+// endRename closes the rename prompt, restarting the countdown if it was
+// paused for it.
+func (m Model) endRename() (Model, tea.Cmd) {
+	m.IsRenaming = false
+	m.TextInput.Blur()
+	if m.renamePaused {
+		m.renamePaused = false
+		return m, callCmd(daemon.CmdResume)
+	}
+	return m, nil
+}
+
+// nextState completes the current session/break and moves on to the next,
+// first asking for a note on focus sessions if note prompts are enabled.
+func (m Model) nextState() (Model, tea.Cmd) {
+	s := m.State
+	if s.NotePrompt && (s.SessionType == pomodoro.Focus || s.SessionType == pomodoro.Flow) {
+		m.Noting = true
+		m.EndedAt = s.At
+		m.NotedElapsed = s.Elapsed
+		m.TextInput.Placeholder = "What did you get done?"
+		m.TextInput.SetValue("")
+		m.TextInput.Focus()
+		return m, textinput.Blink
+	}
+	return m, callCmd(daemon.CmdComplete)
 }
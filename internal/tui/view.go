@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+
+	"github.com/zjom/pom/internal/pomodoro"
 )
 
 func (m Model) View() string {
@@ -12,68 +14,149 @@ func (m Model) View() string {
 		return ""
 	}
 
+	s := m.State
+	flow := s.SessionType == pomodoro.Flow
+
 	var displayTime time.Duration
-	if m.IsPaused || m.IsRenaming {
-		displayTime = m.TimeLeft
+	if s.Waiting {
+		displayTime = s.Total
+	} else if flow && m.Noting {
+		displayTime = m.NotedElapsed
+	} else if flow {
+		displayTime = s.Elapsed
 	} else {
-		displayTime = time.Until(m.TargetTime)
-	}
-	if displayTime < 0 {
-		displayTime = 0
+		displayTime = s.Remaining
 	}
 
 	mins := int(displayTime.Minutes())
 	secs := int(displayTime.Seconds()) % 60
 	timeStr := fmt.Sprintf("%02d:%02d", mins, secs)
 
-	percent := 1.0 - (float64(displayTime) / float64(m.TotalDuration))
-	if percent < 0 {
+	var percent float64
+	if !flow {
+		percent = 1.0 - (float64(displayTime) / float64(s.Total))
+	}
+	if s.Total == 0 || percent < 0 {
 		percent = 0
 	} else if percent > 1.0 {
 		percent = 1.0
 	}
 
 	titleText := "🍅 Pomodoro Timer"
-	if m.Cfg.SessionName != "" {
-		titleText += fmt.Sprintf(" - %s", m.Cfg.SessionName)
+	if m.Project != nil {
+		titleText += " - " + lipgloss.NewStyle().Foreground(lipgloss.Color(m.Project.Color)).Render(m.Project.Name)
+	}
+	if s.Name != "" {
+		titleText += fmt.Sprintf(" - %s", s.Name)
+	}
+	for _, tag := range s.Tags {
+		titleText += " #" + tag
 	}
 
-	statusText := string(m.CurrentType)
-	if m.IsPaused {
+	statusText := string(s.SessionType)
+	if s.Waiting {
+		statusText = "Up next: " + statusText
+	}
+	if s.Profile != "" {
+		statusText += fmt.Sprintf(" [%s]", s.Profile)
+	}
+	if s.Steps > 0 {
+		statusText += fmt.Sprintf(" · step %d/%d", s.Step, s.Steps)
+		if s.Rounds > 0 {
+			statusText += fmt.Sprintf(" · round %d/%d", s.Round, s.Rounds)
+		}
+	}
+	if s.Extended > 0 {
+		statusText += fmt.Sprintf(" (+%s)", s.Extended)
+	}
+	if s.Paused && !m.IsRenaming {
 		statusText += " (PAUSED)"
 	}
 
-	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("205")).MarginBottom(1)
-	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("69"))
-	timerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("43")).Padding(0, 1)
-	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).MarginTop(2)
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.Cfg.Theme.Title)).MarginBottom(1)
+	statusStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.Cfg.Theme.Status))
+	timerStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(m.Cfg.Theme.Timer)).Padding(0, 1)
+	helpStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(m.Cfg.Theme.Help)).MarginTop(2)
+
+	var internal, external int
+	for _, in := range s.Interruptions {
+		if in.Kind == pomodoro.Internal {
+			internal++
+		} else {
+			external++
+		}
+	}
+
+	header := titleStyle.Render(titleText)
+	if m.Streak.Goal.IsSet() {
+		header = titleStyle.MarginBottom(0).Render(titleText) + "\n" +
+			statusStyle.MarginBottom(1).Render(fmt.Sprintf("🔥 %d-day streak · Today: %s (%.0f%%)",
+				m.Streak.Current(), m.Streak.Progress(), m.Streak.Percent()))
+	}
 
 	ui := fmt.Sprintf(
-		"%s\nStatus: %s\nTime: %s\n\n%s\n\nSessions Completed: %d\n",
-		titleStyle.Render(titleText),
+		"%s\nStatus: %s\nTime: %s\n\n%s\n\nSessions Completed: %d\nInterruptions: %d internal • %d external\n",
+		header,
 		statusStyle.Render(statusText),
 		timerStyle.Render(timeStr),
 		m.Progress.ViewAs(percent),
-		m.SessionsDone,
+		s.SessionsDone,
+		internal,
+		external,
 	)
+	if m.Project != nil && m.Project.WeeklyGoal > 0 {
+		done := m.WeekFocus
+		if !s.Waiting && (s.SessionType == pomodoro.Focus || flow) {
+			done += s.Elapsed
+		}
+		ui += fmt.Sprintf("Weekly Goal: %s / %s (%.0f%%)\n",
+			pomodoro.FormatDuration(done), pomodoro.FormatDuration(m.Project.WeeklyGoal), 100*done.Hours()/m.Project.WeeklyGoal.Hours())
+	}
 
-	if m.IsRenaming {
+	if m.Noting {
+		ui += fmt.Sprintf("\nSession Note:\n%s\n\n%s",
+			m.TextInput.View(),
+			helpStyle.Render("(Enter to save, Esc to skip)"),
+		)
+	} else if m.IsRenaming {
 		ui += fmt.Sprintf("\nRename Session:\n%s\n\n%s",
 			m.TextInput.View(),
 			helpStyle.Render("(Enter to save, Esc to cancel)"),
 		)
+	} else if s.Waiting {
+		ui += helpStyle.Render("[enter] start • [s] skip • [d] detach • [q] quit")
+	} else if m.Interrupting != "" {
+		ui += fmt.Sprintf("\nLog %s interruption:\n%s\n\n%s",
+			m.Interrupting,
+			m.TextInput.View(),
+			helpStyle.Render("(Enter to save, Esc to log without a note)"),
+		)
 	} else {
 		if m.ShowHelp {
-			helpText := "Shortcuts:\n" +
-				"  [space/p] Pause / Resume\n" +
+			helpText := "Shortcuts:\n"
+			if flow {
+				helpText += "  [enter]   End Flow Session\n"
+			}
+			helpText += "  [space/p] Pause / Resume\n" +
 				"  [r]       Rename Session\n" +
+				"  [']       Log Internal Interruption\n" +
+				"  [-]       Log External Interruption\n" +
+				"  [s]       Skip to Next Interval\n" +
+				"  [e/E]     Extend by 1 / 5 Minutes\n" +
+				"  [R]       Restart Interval\n" +
 				"  [?]       Hide Help\n" +
-				"  [q]       Quit"
+				"  [d]       Detach, leaving the timer running\n" +
+				"  [q]       Quit, stopping the timer"
 			ui += helpStyle.Render(helpText)
+		} else if flow {
+			ui += helpStyle.Render("[enter] end focus • [?] show help • [d] detach • [q] quit")
 		} else {
-			ui += helpStyle.Render("[?] show help • [q] quit")
+			ui += helpStyle.Render("[?] show help • [d] detach • [q] quit")
 		}
 	}
+	if m.Err != nil {
+		ui += fmt.Sprintf("\n\nError: %v", m.Err)
+	}
 
 	uiBox := lipgloss.NewStyle().Align(lipgloss.Center).Render(ui)
 	return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, uiBox)